@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	registryAliasesPath = "./.github/registry-aliases.yaml"
+
+	// registryRedirectsPath is where the redirects generated from the alias file are written. The registry server
+	// and the Registry site read it to keep serving resources from their old paths.
+	registryRedirectsPath = "./.well-known/registry/redirects.json"
+)
+
+// registryAliases is the schema of the alias file. It records the old paths of renamed namespaces and moved modules,
+// so that module sources and links that use an old path keep working.
+type registryAliases struct {
+	// Namespaces maps an old namespace name to its new name.
+	Namespaces map[string]string `yaml:"namespaces"`
+	// Modules maps an old "<namespace>/<module>" path to its new path.
+	Modules map[string]string `yaml:"modules"`
+}
+
+// registryRedirects is the document generated from the alias file. Namespace redirects apply to every path under the
+// namespace, while module redirects list every old module path explicitly, including the modules of renamed
+// namespaces, so that consumers can look up a module source without applying any rules of their own.
+type registryRedirects struct {
+	Namespaces map[string]string `json:"namespaces"`
+	Modules    map[string]string `json:"modules"`
+}
+
+// resolveModule returns the current "<namespace>/<module>" path of an old module path, and whether it was aliased.
+// Module aliases take precedence over the alias of their namespace.
+func (a registryAliases) resolveModule(key string) (string, bool) {
+	if target, ok := a.Modules[key]; ok {
+		return target, true
+	}
+	namespace, moduleName, ok := strings.Cut(key, "/")
+	if !ok {
+		return key, false
+	}
+	if target, ok := a.Namespaces[namespace]; ok {
+		return target + "/" + moduleName, true
+	}
+	return key, false
+}
+
+func loadRegistryAliases() (registryAliases, error) {
+	aliases := registryAliases{}
+	content, err := os.ReadFile(registryAliasesPath)
+	if err != nil {
+		return aliases, err
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&aliases); err != nil {
+		return aliases, addFilePathToError(registryAliasesPath, err)
+	}
+	return aliases, nil
+}
+
+// validateRegistryAliases checks the alias file against the namespaces and "<namespace>/<module>" paths that exist
+// in the registry. Old names can never be reused, because that would silently take over the sources of everyone who
+// still uses the old path.
+func validateRegistryAliases(aliases registryAliases, namespaces []string, modules []string) []error {
+	var errs []error
+
+	oldNamespaces := make([]string, 0, len(aliases.Namespaces))
+	for oldName := range aliases.Namespaces {
+		oldNamespaces = append(oldNamespaces, oldName)
+	}
+	slices.Sort(oldNamespaces)
+	for _, oldName := range oldNamespaces {
+		newName := aliases.Namespaces[oldName]
+		switch {
+		case !validNameRe.MatchString(oldName):
+			errs = append(errs, xerrors.Errorf("namespace alias %q is not a valid namespace name", oldName))
+		case slices.Contains(namespaces, oldName):
+			errs = append(errs, xerrors.Errorf("namespace %q is aliased to %q, so the old name cannot be used by a namespace again", oldName, newName))
+		}
+		switch {
+		case !validNamespaceRe.MatchString(newName):
+			errs = append(errs, xerrors.Errorf("namespace alias %q must point to a lowercase namespace name, got %q", oldName, newName))
+		case !slices.Contains(namespaces, newName):
+			errs = append(errs, xerrors.Errorf("namespace alias %q points to namespace %q, which does not exist", oldName, newName))
+		}
+	}
+
+	oldModules := make([]string, 0, len(aliases.Modules))
+	for oldPath := range aliases.Modules {
+		oldModules = append(oldModules, oldPath)
+	}
+	slices.Sort(oldModules)
+	for _, oldPath := range oldModules {
+		newPath := aliases.Modules[oldPath]
+		oldNamespace, oldName, ok := strings.Cut(oldPath, "/")
+		switch {
+		case !ok || !validNameRe.MatchString(oldNamespace) || !validNameRe.MatchString(oldName):
+			errs = append(errs, xerrors.Errorf("module alias %q must have the form \"<namespace>/<module>\"", oldPath))
+		case slices.Contains(modules, oldPath):
+			errs = append(errs, xerrors.Errorf("module %q is aliased to %q, so the old path cannot be used by a module again", oldPath, newPath))
+		}
+		if newNamespace, _, _ := strings.Cut(newPath, "/"); !validNamespaceRe.MatchString(newNamespace) {
+			errs = append(errs, xerrors.Errorf("module alias %q must point to a module in a lowercase namespace, got %q", oldPath, newPath))
+		} else if !slices.Contains(modules, newPath) {
+			errs = append(errs, xerrors.Errorf("module alias %q points to module %q, which does not exist", oldPath, newPath))
+		}
+	}
+
+	for i, err := range errs {
+		errs[i] = addFilePathToError(registryAliasesPath, err)
+	}
+	return errs
+}
+
+// buildRegistryRedirects expands the alias file into the redirects document, given every "<namespace>/<module>"
+// path in the registry.
+func buildRegistryRedirects(aliases registryAliases, modules []string) registryRedirects {
+	redirects := registryRedirects{Namespaces: map[string]string{}, Modules: map[string]string{}}
+	for oldName, newName := range aliases.Namespaces {
+		redirects.Namespaces[oldName] = newName
+		for _, m := range modules {
+			if moduleName, ok := strings.CutPrefix(m, newName+"/"); ok {
+				redirects.Modules[oldName+"/"+moduleName] = m
+			}
+		}
+	}
+	for oldPath, newPath := range aliases.Modules {
+		redirects.Modules[oldPath] = newPath
+	}
+	return redirects
+}
+
+// renderRegistryRedirects renders the redirects document. Go sorts map keys when encoding JSON, so the output is
+// stable between runs.
+func renderRegistryRedirects(redirects registryRedirects) ([]byte, error) {
+	content, err := json.MarshalIndent(redirects, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+// registryNamespacesAndModules returns the name of every namespace directory, and the "<namespace>/<module>" path of
+// every module directory in the registry.
+func registryNamespacesAndModules() (namespaces []string, modules []string, err error) {
+	entries, err := os.ReadDir(rootRegistryPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		namespaces = append(namespaces, e.Name())
+		moduleEntries, err := os.ReadDir(path.Join(rootRegistryPath, e.Name(), "modules"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, nil, err
+		}
+		for _, m := range moduleEntries {
+			if m.IsDir() {
+				modules = append(modules, e.Name()+"/"+m.Name())
+			}
+		}
+	}
+	return namespaces, modules, nil
+}
+
+// validateAllRegistryAliases validates the alias file, and that the committed redirects file is generated from it.
+func validateAllRegistryAliases() error {
+	aliases, err := loadRegistryAliases()
+	if err != nil {
+		return validationPhaseError{
+			phase:  validationPhaseCrossReference,
+			errors: []error{err},
+		}
+	}
+	namespaces, modules, err := registryNamespacesAndModules()
+	if err != nil {
+		return err
+	}
+
+	errs := validateRegistryAliases(aliases, namespaces, modules)
+	generated, err := renderRegistryRedirects(buildRegistryRedirects(aliases, modules))
+	if err != nil {
+		return err
+	}
+	committed, err := os.ReadFile(registryRedirectsPath)
+	if err != nil {
+		errs = append(errs, addFilePathToError(registryRedirectsPath, err))
+	} else if string(committed) != string(generated) {
+		errs = append(errs, xerrors.Errorf("%q: file is out of date with %q; regenerate it with \"./readmevalidation redirects\"", registryRedirectsPath, registryAliasesPath))
+	}
+	if len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseCrossReference,
+			errors: errs,
+		}
+	}
+	logger.Info(context.Background(), "processed all registry aliases as valid", "num_namespaces", len(aliases.Namespaces), "num_modules", len(aliases.Modules))
+	return nil
+}
+
+// writeRegistryRedirects regenerates the redirects file from the alias file.
+func writeRegistryRedirects() error {
+	aliases, err := loadRegistryAliases()
+	if err != nil {
+		return err
+	}
+	namespaces, modules, err := registryNamespacesAndModules()
+	if err != nil {
+		return err
+	}
+	if errs := validateRegistryAliases(aliases, namespaces, modules); len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseCrossReference,
+			errors: errs,
+		}
+	}
+
+	redirects := buildRegistryRedirects(aliases, modules)
+	content, err := renderRegistryRedirects(redirects)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(registryRedirectsPath), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(registryRedirectsPath, content, 0o644); err != nil {
+		return err
+	}
+	logger.Info(context.Background(), "generated registry redirects", "path", registryRedirectsPath, "num_namespaces", len(redirects.Namespaces), "num_modules", len(redirects.Modules))
+	return nil
+}
+
+func runRedirectsCommand(_ []string) error {
+	return writeRegistryRedirects()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateRegistryAliases(t *testing.T) {
+	t.Parallel()
+
+	namespaces := []string{"aj0070", "coder", "Mixed"}
+	modules := []string{"aj0070/pgadmin", "coder/code-server"}
+
+	testCases := []struct {
+		name     string
+		aliases  registryAliases
+		expected []string
+	}{
+		{
+			name: "valid aliases",
+			aliases: registryAliases{
+				Namespaces: map[string]string{"AJ0070": "aj0070"},
+				Modules:    map[string]string{"acme/code-server": "coder/code-server"},
+			},
+		},
+		{
+			name:     "reused namespace name",
+			aliases:  registryAliases{Namespaces: map[string]string{"coder": "aj0070"}},
+			expected: []string{`namespace "coder" is aliased to "aj0070", so the old name cannot be used by a namespace again`},
+		},
+		{
+			name:     "mixed-case target",
+			aliases:  registryAliases{Namespaces: map[string]string{"AJ0070": "Mixed"}},
+			expected: []string{`namespace alias "AJ0070" must point to a lowercase namespace name, got "Mixed"`},
+		},
+		{
+			name:     "missing namespace target",
+			aliases:  registryAliases{Namespaces: map[string]string{"Acme": "acme"}},
+			expected: []string{`namespace alias "Acme" points to namespace "acme", which does not exist`},
+		},
+		{
+			name:     "reused module path",
+			aliases:  registryAliases{Modules: map[string]string{"aj0070/pgadmin": "coder/code-server"}},
+			expected: []string{`module "aj0070/pgadmin" is aliased to "coder/code-server", so the old path cannot be used by a module again`},
+		},
+		{
+			name:    "invalid and missing module paths",
+			aliases: registryAliases{Modules: map[string]string{"code-server": "coder/missing", "acme/pgadmin": "AJ0070/pgadmin"}},
+			expected: []string{
+				`module alias "acme/pgadmin" must point to a module in a lowercase namespace, got "AJ0070/pgadmin"`,
+				`module alias "code-server" must have the form "<namespace>/<module>"`,
+				`module alias "code-server" points to module "coder/missing", which does not exist`,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			errs := validateRegistryAliases(tc.aliases, namespaces, modules)
+			if len(errs) != len(tc.expected) {
+				t.Fatalf("expected %d errors, got: %v", len(tc.expected), errs)
+			}
+			for i, expected := range tc.expected {
+				if !strings.Contains(errs[i].Error(), expected) {
+					t.Errorf("expected error containing %q, got: %v", expected, errs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRegistryRedirects(t *testing.T) {
+	t.Parallel()
+
+	aliases := registryAliases{
+		Namespaces: map[string]string{"AJ0070": "aj0070"},
+		Modules:    map[string]string{"AJ0070/old-pgadmin": "aj0070/pgadmin", "acme/vscode": "coder/vscode-web"},
+	}
+	for key, expected := range map[string]string{
+		"AJ0070/old-pgadmin": "aj0070/pgadmin",
+		"AJ0070/pgadmin":     "aj0070/pgadmin",
+		"acme/vscode":        "coder/vscode-web",
+		"coder/code-server":  "",
+	} {
+		target, aliased := aliases.resolveModule(key)
+		if aliased != (expected != "") || (aliased && target != expected) {
+			t.Errorf("expected %q to resolve to %q, got %q (aliased: %v)", key, expected, target, aliased)
+		}
+	}
+
+	content, err := renderRegistryRedirects(buildRegistryRedirects(aliases, []string{"aj0070/pgadmin", "aj0070/adminer", "coder/vscode-web"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{
+  "namespaces": {
+    "AJ0070": "aj0070"
+  },
+  "modules": {
+    "AJ0070/adminer": "aj0070/adminer",
+    "AJ0070/old-pgadmin": "aj0070/pgadmin",
+    "AJ0070/pgadmin": "aj0070/pgadmin",
+    "acme/vscode": "coder/vscode-web"
+  }
+}
+`
+	if string(content) != expected {
+		t.Errorf("unexpected redirects:\n%s", content)
+	}
+	if got := releaseTreePath("release/AJ0070/pgadmin/v1.0.0"); got != "registry/AJ0070/modules/pgadmin/" {
+		t.Errorf("unexpected release tree path %q", got)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"golang.org/x/xerrors"
+)
+
+// catalogResource is a module or template as seen by the tools that browse the registry, with its README and
+// Terraform configuration loaded side by side.
+type catalogResource struct {
+	resourceType string
+	namespace    string
+	name         string
+	rawReadme    string
+	readme       coderResourceReadme
+	terraform    coderResourceTerraform
+}
+
+// id returns the "<namespace>/<name>" identifier of a resource, which is unique within its resource type.
+func (r catalogResource) id() string {
+	return r.namespace + "/" + r.name
+}
+
+// registrySource returns the module source that templates use to reference a module.
+func (r catalogResource) registrySource() string {
+	return registryModuleSourcePrefix + r.id() + "/coder"
+}
+
+// terraformVariable describes an input variable declared by a module or template.
+type terraformVariable struct {
+	Name        string          `json:"name"`
+	Type        string          `json:"type,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Default     json.RawMessage `json:"default,omitempty"`
+	Required    bool            `json:"required"`
+	Sensitive   bool            `json:"sensitive,omitempty"`
+}
+
+// expressionSource returns the source text of an expression as written in its file.
+func (f terraformFile) expressionSource(expr hclsyntax.Expression) string {
+	return string(expr.Range().SliceBytes(f.src))
+}
+
+// variables returns every input variable declared by the configuration, in declaration order.
+func (t coderResourceTerraform) variables() []terraformVariable {
+	var found []terraformVariable
+	for _, f := range t.files {
+		for _, b := range f.body.Blocks {
+			if b.Type != "variable" || len(b.Labels) != 1 {
+				continue
+			}
+
+			v := terraformVariable{Name: b.Labels[0], Required: true}
+			attrs := b.Body.Attributes
+			if attr, ok := attrs["type"]; ok {
+				v.Type = f.expressionSource(attr.Expr)
+			}
+			if attr, ok := attrs["description"]; ok {
+				v.Description, _ = literalString(attr.Expr)
+			}
+			if attr, ok := attrs["sensitive"]; ok {
+				v.Sensitive, _ = literalBool(attr.Expr)
+			}
+			if attr, ok := attrs["default"]; ok {
+				v.Required = false
+				v.Default = variableDefaultJSON(f, attr.Expr)
+			}
+			found = append(found, v)
+		}
+	}
+	return found
+}
+
+// variableDefaultJSON renders a variable's default as JSON. Defaults that can't be evaluated without context, like
+// heredocs with interpolations, are rendered as their source text.
+func variableDefaultJSON(f terraformFile, expr hclsyntax.Expression) json.RawMessage {
+	if val, diags := expr.Value(nil); !diags.HasErrors() && val.IsWhollyKnown() {
+		if encoded, err := ctyjson.Marshal(val, val.Type()); err == nil {
+			return encoded
+		}
+	}
+	encoded, _ := json.Marshal(f.expressionSource(expr))
+	return encoded
+}
+
+// usageBlock returns a ready-to-paste Terraform block that adds a module to a template. The block is taken from the
+// module README's own usage example when it has one, and otherwise generated from the module's required variables.
+func (r catalogResource) usageBlock() (string, error) {
+	if r.resourceType != "modules" {
+		return "", xerrors.Errorf("usage blocks are only available for modules, not %s", r.resourceType)
+	}
+
+	isInsideTerraform := false
+	var block strings.Builder
+	for _, line := range strings.Split(r.readme.body, "\n") {
+		if strings.HasPrefix(line, "```") {
+			if isInsideTerraform && strings.Contains(block.String(), r.registrySource()) {
+				return block.String(), nil
+			}
+			isInsideTerraform = !isInsideTerraform && strings.HasPrefix(line, "```tf")
+			block.Reset()
+			continue
+		}
+		if isInsideTerraform {
+			block.WriteString(line + "\n")
+		}
+	}
+
+	version, ok := readmeModuleVersion(r.rawReadme)
+	if !ok {
+		version = "1.0.0"
+	}
+	lines := [][2]string{
+		{"source", `"` + r.registrySource() + `"`},
+		{"version", `"` + version + `"`},
+	}
+	for _, v := range r.terraform.variables() {
+		if !v.Required {
+			continue
+		}
+		value := `"" # TODO: ` + v.Description
+		if v.Name == "agent_id" {
+			value = "coder_agent.main.id"
+		}
+		lines = append(lines, [2]string{v.Name, value})
+	}
+
+	width := 0
+	for _, l := range lines {
+		width = max(width, len(l[0]))
+	}
+	block.Reset()
+	block.WriteString("module \"" + r.name + "\" {\n")
+	for _, l := range lines {
+		block.WriteString("  " + l[0] + strings.Repeat(" ", width-len(l[0])) + " = " + l[1] + "\n")
+	}
+	block.WriteString("}\n")
+	return block.String(), nil
+}
+
+// loadCatalog loads every module and template in the registry.
+func loadCatalog() ([]catalogResource, error) {
+	var catalog []catalogResource
+	for _, resourceType := range supportedResourceTypes {
+		allReadmeFiles, err := aggregateCoderResourceReadmeFiles(resourceType)
+		if err != nil {
+			return nil, err
+		}
+		rawReadmes := map[string]string{}
+		for _, rm := range allReadmeFiles {
+			rawReadmes[rm.filePath] = rm.rawText
+		}
+		readmes, err := parseCoderResourceReadmeFiles(resourceType, allReadmeFiles)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rm := range readmes {
+			dirPath := path.Dir(rm.filePath)
+			tf, errs := parseCoderResourceTerraform(resourceType, dirPath)
+			if len(errs) != 0 {
+				return nil, validationPhaseError{
+					phase:  validationPhaseTerraform,
+					errors: errs,
+				}
+			}
+			catalog = append(catalog, catalogResource{
+				resourceType: resourceType,
+				namespace:    resourceNamespace(rm.filePath),
+				name:         path.Base(dirPath),
+				rawReadme:    rawReadmes[rm.filePath],
+				readme:       rm,
+				terraform:    tf,
+			})
+		}
+	}
+	return catalog, nil
+}
+
+// findCatalogResource looks up a resource by type and "<namespace>/<name>" identifier.
+func findCatalogResource(catalog []catalogResource, resourceType string, id string) (catalogResource, error) {
+	idx := slices.IndexFunc(catalog, func(r catalogResource) bool {
+		return r.resourceType == resourceType && r.id() == id
+	})
+	if idx == -1 {
+		return catalogResource{}, xerrors.Errorf("no %s with ID %q (expected \"<namespace>/<name>\")", strings.TrimSuffix(resourceType, "s"), id)
+	}
+	return catalog[idx], nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const testModuleTerraform = `
+variable "agent_id" {
+  type        = string
+  description = "The ID of a Coder agent."
+}
+
+variable "port" {
+  type    = number
+  default = 13337
+}
+
+variable "settings" {
+  type      = map(string)
+  default   = { theme = "dark" }
+  sensitive = true
+}
+
+variable "folder" {
+  type    = string
+  default = "/home/${local.user}"
+}
+`
+
+func newTestCatalogResource(t *testing.T, body string) catalogResource {
+	t.Helper()
+
+	tf := parseTestTerraform(t, testModuleTerraform)
+	tf.resourceType = "modules"
+	displayName := "Code Server"
+	return catalogResource{
+		resourceType: "modules",
+		namespace:    "acme",
+		name:         "code-server",
+		rawReadme:    "---\ndisplay_name: Code Server\n---\n\n" + body,
+		readme: coderResourceReadme{
+			resourceType: "modules",
+			filePath:     "registry/acme/modules/code-server/README.md",
+			body:         body,
+			frontmatter: coderResourceFrontmatter{
+				DisplayName:      &displayName,
+				Description:      "VS Code in the browser",
+				Tags:             []string{"ide", "web"},
+				OperatingSystems: []string{"linux", "macos"},
+			},
+		},
+		terraform: tf,
+	}
+}
+
+func TestTerraformVariables(t *testing.T) {
+	t.Parallel()
+
+	r := newTestCatalogResource(t, "# Code Server\n")
+	got, err := json.Marshal(r.terraform.variables())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `[{"name":"agent_id","type":"string","description":"The ID of a Coder agent.","required":true},` +
+		`{"name":"port","type":"number","default":13337,"required":false},` +
+		`{"name":"settings","type":"map(string)","default":{"theme":"dark"},"required":false,"sensitive":true},` +
+		`{"name":"folder","type":"string","default":"\"/home/${local.user}\"","required":false}]`
+	if string(got) != expected {
+		t.Errorf("Unexpected variables:\n got: %s\nwant: %s", got, expected)
+	}
+}
+
+func TestCatalogResourceUsageBlock(t *testing.T) {
+	t.Parallel()
+
+	fromReadme := "# Code Server\n\n```tf\nterraform {}\n```\n\n```tf\nmodule \"code-server\" {\n  source  = \"registry.coder.com/acme/code-server/coder\"\n  version = \"1.2.3\"\n}\n```\n"
+	usage, err := newTestCatalogResource(t, fromReadme).usageBlock()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "module \"code-server\" {\n  source  = \"registry.coder.com/acme/code-server/coder\"\n  version = \"1.2.3\"\n}\n"
+	if usage != expected {
+		t.Errorf("Unexpected usage block from README:\n%s", usage)
+	}
+
+	usage, err = newTestCatalogResource(t, "# Code Server\n").usageBlock()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected = "module \"code-server\" {\n  source   = \"registry.coder.com/acme/code-server/coder\"\n  version  = \"1.0.0\"\n  agent_id = coder_agent.main.id\n}\n"
+	if usage != expected {
+		t.Errorf("Unexpected generated usage block:\n%s", usage)
+	}
+}
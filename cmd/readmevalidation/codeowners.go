@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	rootCodeownersPath      = "./CODEOWNERS"
+	codeownersOverridesPath = "./.github/codeowners-overrides.yaml"
+
+	codeownersHeader = `# This file is generated from the "github" field of every contributor profile in registry/<namespace>/README.md,
+# plus the overrides in .github/codeowners-overrides.yaml. Do not edit it by hand; instead, run:
+#
+#   go build ./cmd/readmevalidation && ./readmevalidation codeowners
+`
+)
+
+// codeownersPathRule is an extra CODEOWNERS entry for a path outside of the namespace directories.
+type codeownersPathRule struct {
+	Comment string   `yaml:"comment"`
+	Path    string   `yaml:"path"`
+	Owners  []string `yaml:"owners"`
+}
+
+// codeownersOverrides is the schema of the CODEOWNERS override file. Namespace overrides replace the owner derived
+// from a namespace's contributor profile, which is mainly useful for assigning GitHub teams.
+type codeownersOverrides struct {
+	Paths      []codeownersPathRule `yaml:"paths"`
+	Namespaces map[string][]string  `yaml:"namespaces"`
+	// Organizations lists the GitHub organizations that contributor profiles name in their "github" field. GitHub
+	// silently ignores CODEOWNERS entries that assign a bare organization, so namespaces owned by one of them need a
+	// namespace override that assigns one of the organization's teams or a maintainer instead.
+	Organizations []string `yaml:"organizations"`
+}
+
+// isOrganization reports whether a CODEOWNERS owner such as "@coder" is a bare login of one of the listed GitHub
+// organizations. Teams ("@coder/maintainers") and email addresses are never organizations.
+func (o codeownersOverrides) isOrganization(owner string) bool {
+	login, ok := strings.CutPrefix(owner, "@")
+	if !ok || strings.Contains(login, "/") {
+		return false
+	}
+	return slices.ContainsFunc(o.Organizations, func(org string) bool {
+		return strings.EqualFold(org, login)
+	})
+}
+
+func loadCodeownersOverrides() (codeownersOverrides, error) {
+	overrides := codeownersOverrides{}
+	content, err := os.ReadFile(codeownersOverridesPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return overrides, nil
+		}
+		return overrides, err
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&overrides); err != nil {
+		return overrides, addFilePathToError(codeownersOverridesPath, err)
+	}
+	return overrides, nil
+}
+
+// namespaceCodeownersPath returns the CODEOWNERS pattern that matches everything inside a namespace directory.
+func namespaceCodeownersPath(namespace string) string {
+	return "/" + path.Join(strings.TrimPrefix(rootRegistryPath, "./"), namespace) + "/"
+}
+
+// generateCodeowners derives the full contents of the CODEOWNERS file. It also returns an error for every namespace
+// that would end up without an owner, or override that points at a namespace that does not exist.
+func generateCodeowners(contributors map[string]contributorProfileReadme, overrides codeownersOverrides) (string, []error) {
+	var errs []error
+	var out strings.Builder
+	out.WriteString(codeownersHeader)
+
+	for _, rule := range overrides.Paths {
+		if rule.Path == "" || len(rule.Owners) == 0 {
+			errs = append(errs, xerrors.Errorf("%q: path overrides must have a path and at least one owner", codeownersOverridesPath))
+			continue
+		}
+		for _, owner := range rule.Owners {
+			if overrides.isOrganization(owner) {
+				errs = append(errs, xerrors.Errorf("%q: path %q is assigned to organization %q, which GitHub ignores; assign one of its teams (\"%s/<team>\") instead", codeownersOverridesPath, rule.Path, owner, owner))
+			}
+		}
+		out.WriteString("\n")
+		if rule.Comment != "" {
+			out.WriteString("# " + rule.Comment + "\n")
+		}
+		out.WriteString(rule.Path + " " + strings.Join(rule.Owners, " ") + "\n")
+	}
+
+	var overriddenNamespaces []string
+	for namespace := range overrides.Namespaces {
+		overriddenNamespaces = append(overriddenNamespaces, namespace)
+	}
+	slices.Sort(overriddenNamespaces)
+	for _, namespace := range overriddenNamespaces {
+		if _, ok := contributors[namespace]; !ok {
+			errs = append(errs, xerrors.Errorf("%q: owner override points at namespace %q, which does not exist", codeownersOverridesPath, namespace))
+		}
+	}
+
+	var namespaces []string
+	for namespace := range contributors {
+		namespaces = append(namespaces, namespace)
+	}
+	slices.Sort(namespaces)
+
+	out.WriteString("\n# Namespace owners\n")
+	for _, namespace := range namespaces {
+		owners, ok := overrides.Namespaces[namespace]
+		if !ok {
+			if username := contributors[namespace].frontmatter.GithubUsername; username != nil && *username != "" {
+				owners = []string{"@" + *username}
+			}
+		}
+		if len(owners) == 0 {
+			errs = append(errs, xerrors.Errorf("%q: namespace has no owner; set the \"github\" field or add an override to %q", contributors[namespace].filePath, codeownersOverridesPath))
+			continue
+		}
+		for _, owner := range owners {
+			if !overrides.isOrganization(owner) {
+				continue
+			}
+			if _, overridden := overrides.Namespaces[namespace]; overridden {
+				errs = append(errs, xerrors.Errorf("%q: namespace %q is assigned to organization %q, which GitHub ignores; assign one of its teams (\"%s/<team>\") instead", codeownersOverridesPath, namespace, owner, owner))
+			} else {
+				errs = append(errs, xerrors.Errorf("%q: namespace is owned by organization %q, which GitHub ignores in CODEOWNERS; add an override with one of its teams or a maintainer to %q", contributors[namespace].filePath, owner, codeownersOverridesPath))
+			}
+		}
+		out.WriteString(namespaceCodeownersPath(namespace) + " " + strings.Join(owners, " ") + "\n")
+	}
+
+	return out.String(), errs
+}
+
+// findStaleCodeownersNamespaces returns an error for every namespace entry in the committed CODEOWNERS file that no
+// longer has a directory in the registry.
+func findStaleCodeownersNamespaces(committed string, contributors map[string]contributorProfileReadme) []error {
+	var errs []error
+	prefix := namespaceCodeownersPath("")
+	lineScanner := bufio.NewScanner(strings.NewReader(committed))
+	for lineScanner.Scan() {
+		pattern, _, _ := strings.Cut(strings.TrimSpace(lineScanner.Text()), " ")
+		namespace, ok := strings.CutPrefix(pattern, prefix)
+		if !ok {
+			continue
+		}
+		namespace, _, _ = strings.Cut(namespace, "/")
+		if _, exists := contributors[namespace]; !exists {
+			errs = append(errs, xerrors.Errorf("%q: entry %q points at namespace %q, which no longer exists", rootCodeownersPath, pattern, namespace))
+		}
+	}
+	return errs
+}
+
+// validateCodeowners validates that every namespace has an owner and that the committed CODEOWNERS file matches the
+// one generated from the contributor profiles.
+func validateCodeowners(contributors map[string]contributorProfileReadme) error {
+	overrides, err := loadCodeownersOverrides()
+	if err != nil {
+		return err
+	}
+
+	generated, errs := generateCodeowners(contributors, overrides)
+	committed, err := os.ReadFile(rootCodeownersPath)
+	if err != nil {
+		errs = append(errs, addFilePathToError(rootCodeownersPath, err))
+	} else {
+		errs = append(errs, findStaleCodeownersNamespaces(string(committed), contributors)...)
+		if len(errs) == 0 && string(committed) != generated {
+			errs = append(errs, xerrors.Errorf("%q: file is out of date with the contributor profiles; regenerate it with \"./readmevalidation codeowners\"", rootCodeownersPath))
+		}
+	}
+
+	if len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseCrossReference,
+			errors: errs,
+		}
+	}
+	return nil
+}
+
+func runCodeownersCommand(_ []string) error {
+	return writeCodeowners()
+}
+
+// writeCodeowners regenerates the root CODEOWNERS file from the contributor profiles.
+func writeCodeowners() error {
+	allReadmeFiles, err := aggregateContributorReadmeFiles()
+	if err != nil {
+		return err
+	}
+	contributors, err := parseContributorFiles(allReadmeFiles)
+	if err != nil {
+		return err
+	}
+	overrides, err := loadCodeownersOverrides()
+	if err != nil {
+		return err
+	}
+
+	generated, errs := generateCodeowners(contributors, overrides)
+	if len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseCrossReference,
+			errors: errs,
+		}
+	}
+	if err := os.WriteFile(rootCodeownersPath, []byte(generated), 0o644); err != nil {
+		return err
+	}
+	logger.Info(context.Background(), "generated CODEOWNERS", "path", rootCodeownersPath, "num_namespaces", len(contributors))
+	return nil
+}
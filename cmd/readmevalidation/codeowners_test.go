@@ -0,0 +1,111 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func newTestContributor(namespace string, github *string) contributorProfileReadme {
+	return contributorProfileReadme{
+		namespace: namespace,
+		filePath:  "registry/" + namespace + "/README.md",
+		frontmatter: contributorProfileFrontmatter{
+			GithubUsername: github,
+		},
+	}
+}
+
+func TestGenerateCodeowners(t *testing.T) {
+	t.Parallel()
+
+	alice := "alice"
+	contributors := map[string]contributorProfileReadme{
+		"zeta":  newTestContributor("zeta", &alice),
+		"alpha": newTestContributor("alpha", nil),
+		"coder": newTestContributor("coder", &alice),
+	}
+	overrides := codeownersOverrides{
+		Paths: []codeownersPathRule{{Comment: "Workflows", Path: ".github/", Owners: []string{"@bob"}}},
+		Namespaces: map[string][]string{
+			"coder":   {"@coder/maintainers"},
+			"removed": {"@carol"},
+		},
+	}
+
+	generated, errs := generateCodeowners(contributors, overrides)
+
+	expectedLines := []string{
+		"# Workflows\n.github/ @bob\n",
+		"/registry/coder/ @coder/maintainers\n/registry/zeta/ @alice\n",
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(generated, line) {
+			t.Errorf("Expected generated CODEOWNERS to contain %q, got:\n%s", line, generated)
+		}
+	}
+	if strings.Contains(generated, "/registry/alpha/") {
+		t.Errorf("Expected namespace without owner to be left out, got:\n%s", generated)
+	}
+
+	expectedErrs := []string{
+		`namespace "removed", which does not exist`,
+		`"registry/alpha/README.md": namespace has no owner`,
+	}
+	for _, expected := range expectedErrs {
+		found := false
+		for _, e := range errs {
+			found = found || strings.Contains(e.Error(), expected)
+		}
+		if !found {
+			t.Errorf("Expected error containing %q, got: %v", expected, errs)
+		}
+	}
+}
+
+func TestFindStaleCodeownersNamespaces(t *testing.T) {
+	t.Parallel()
+
+	contributors := map[string]contributorProfileReadme{
+		"coder": newTestContributor("coder", nil),
+	}
+	committed := "# Namespace owners\n/registry/coder/ @coder\n/registry/gone/ @someone\n.github/ @bob\n"
+
+	errs := findStaleCodeownersNamespaces(committed, contributors)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `namespace "gone", which no longer exists`) {
+		t.Errorf("Expected a single stale namespace error, got: %v", errs)
+	}
+}
+
+func TestGenerateCodeownersOrganizations(t *testing.T) {
+	t.Parallel()
+
+	coder := "coder"
+	contributors := map[string]contributorProfileReadme{
+		"coder":      newTestContributor("coder", &coder),
+		"coder-labs": newTestContributor("coder-labs", &coder),
+		"acme":       newTestContributor("acme", &coder),
+	}
+	overrides := codeownersOverrides{
+		Paths: []codeownersPathRule{{Path: ".github/", Owners: []string{"@Coder", "@coder/security"}}},
+		Namespaces: map[string][]string{
+			"coder":      {"@coder/maintainers"},
+			"coder-labs": {"@coder"},
+		},
+		Organizations: []string{"coder"},
+	}
+
+	_, errs := generateCodeowners(contributors, overrides)
+	expectedErrs := []string{
+		`path ".github/" is assigned to organization "@Coder", which GitHub ignores`,
+		`"registry/acme/README.md": namespace is owned by organization "@coder", which GitHub ignores in CODEOWNERS`,
+		`namespace "coder-labs" is assigned to organization "@coder", which GitHub ignores`,
+	}
+	if len(errs) != len(expectedErrs) {
+		t.Fatalf("Expected %d errors, got: %v", len(expectedErrs), errs)
+	}
+	for i, expected := range expectedErrs {
+		if !strings.Contains(errs[i].Error(), expected) {
+			t.Errorf("Expected error containing %q, got: %v", expected, errs[i])
+		}
+	}
+}
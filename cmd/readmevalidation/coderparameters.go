@@ -0,0 +1,251 @@
+package main
+
+import (
+	"regexp"
+	"slices"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/xerrors"
+)
+
+// imageAttributeNames lists the attribute names that select a base image or machine image for a workspace, across
+// the providers used by templates in this repo. A coder_parameter referenced by one of these attributes is treated
+// as an image selection parameter.
+var imageAttributeNames = []string{"image", "image_id", "image_name", "source_image", "source_image_id", "base_image", "ami"}
+
+// parameterOption is a single static option block inside a coder_parameter.
+type parameterOption struct {
+	name  string
+	value string
+	line  int
+}
+
+// collectImageParameters returns the names of every coder_parameter data source that feeds into an image attribute,
+// either directly or through any number of local values.
+func collectImageParameters(tf coderResourceTerraform) []string {
+	locals := tf.locals()
+
+	var params []string
+	visitedLocals := map[string]bool{}
+	var visitExpr func(expr hclsyntax.Expression)
+	visitExpr = func(expr hclsyntax.Expression) {
+		for _, name := range referencedDataSources(expr, "coder_parameter") {
+			if !slices.Contains(params, name) {
+				params = append(params, name)
+			}
+		}
+		for _, name := range referencedNames(expr, "local") {
+			attr, ok := locals[name]
+			if !ok || visitedLocals[name] {
+				continue
+			}
+			visitedLocals[name] = true
+			visitExpr(attr.Expr)
+		}
+	}
+
+	var visitBody func(body *hclsyntax.Body)
+	visitBody = func(body *hclsyntax.Body) {
+		for name, attr := range body.Attributes {
+			if slices.Contains(imageAttributeNames, name) {
+				visitExpr(attr.Expr)
+			}
+		}
+		for _, b := range body.Blocks {
+			visitBody(b.Body)
+		}
+	}
+	for _, b := range tf.blocks("resource") {
+		visitBody(b.block.Body)
+	}
+	for _, b := range tf.blocks("data") {
+		// A parameter's own default is not a use of it, and image parameters frequently have an "image" option.
+		if len(b.block.Labels) == 0 || b.block.Labels[0] == "coder_parameter" {
+			continue
+		}
+		visitBody(b.block.Body)
+	}
+	return params
+}
+
+func validateCoderParameterOptions(body *hclsyntax.Body) ([]parameterOption, []error) {
+	var options []parameterOption
+	var errs []error
+	firstLineByValue := map[string]int{}
+	firstLineByName := map[string]int{}
+
+	for _, b := range body.Blocks {
+		if b.Type != "option" {
+			continue
+		}
+
+		opt := parameterOption{line: b.DefRange().Start.Line}
+		valueAttr, ok := b.Body.Attributes["value"]
+		if !ok {
+			errs = append(errs, addRangeToError(b.DefRange(), xerrors.New("option is missing required 'value' attribute")))
+			continue
+		}
+		value, isLiteral := literalString(valueAttr.Expr)
+		if !isLiteral {
+			continue
+		}
+		opt.value = value
+		if nameAttr, ok := b.Body.Attributes["name"]; ok {
+			opt.name, _ = literalString(nameAttr.Expr)
+		}
+
+		if firstLine, exists := firstLineByValue[opt.value]; exists {
+			errs = append(errs, addRangeToError(valueAttr.SrcRange, xerrors.Errorf("option value %q is duplicated (first defined on line %d)", opt.value, firstLine)))
+		} else {
+			firstLineByValue[opt.value] = opt.line
+		}
+		if opt.name != "" {
+			if firstLine, exists := firstLineByName[opt.name]; exists {
+				errs = append(errs, addRangeToError(b.DefRange(), xerrors.Errorf("option name %q is duplicated (first defined on line %d)", opt.name, firstLine)))
+			} else {
+				firstLineByName[opt.name] = opt.line
+			}
+		}
+		options = append(options, opt)
+	}
+	return options, errs
+}
+
+func validateCoderParameterValidationBlocks(body *hclsyntax.Body, paramType string, defaultValue *string) []error {
+	var errs []error
+	for _, b := range body.Blocks {
+		if b.Type != "validation" {
+			continue
+		}
+
+		if regexAttr, ok := b.Body.Attributes["regex"]; ok {
+			if paramType != "" && paramType != "string" {
+				errs = append(errs, addRangeToError(regexAttr.SrcRange, xerrors.Errorf("validation regex is only supported for string parameters, not %q", paramType)))
+			}
+			if pattern, isLiteral := literalString(regexAttr.Expr); isLiteral {
+				re, err := regexp.Compile(pattern)
+				switch {
+				case err != nil:
+					errs = append(errs, addRangeToError(regexAttr.SrcRange, xerrors.Errorf("validation regex %q is invalid: %v", pattern, err)))
+				case defaultValue != nil && !re.MatchString(*defaultValue):
+					errs = append(errs, addRangeToError(regexAttr.SrcRange, xerrors.Errorf("default value %q does not match validation regex %q", *defaultValue, pattern)))
+				}
+			}
+		}
+
+		minAttr, hasMin := b.Body.Attributes["min"]
+		maxAttr, hasMax := b.Body.Attributes["max"]
+		if !hasMin || !hasMax {
+			continue
+		}
+		minVal, minOk := literalValue(minAttr.Expr)
+		maxVal, maxOk := literalValue(maxAttr.Expr)
+		if !minOk || !maxOk || minVal.Type() != cty.Number || maxVal.Type() != cty.Number {
+			continue
+		}
+		if minVal.GreaterThan(maxVal).True() {
+			errs = append(errs, addRangeToError(b.DefRange(), xerrors.Errorf("validation min (%s) is greater than max (%s)", minVal.AsBigFloat().String(), maxVal.AsBigFloat().String())))
+		}
+	}
+	return errs
+}
+
+// isLiteralFalse reports whether an expression is the literal false.
+func isLiteralFalse(expr hclsyntax.Expression) bool {
+	value, isLiteral := literalBool(expr)
+	return isLiteral && !value
+}
+
+// validateCoderParameter enforces the invariants of a single coder_parameter data source that would otherwise only
+// surface when someone clicks through the workspace creation form.
+func validateCoderParameter(param terraformBlock, imageParams []string) []error {
+	body := param.block.Body
+	paramName := param.block.Labels[1]
+
+	var errs []error
+	options, optionErrs := validateCoderParameterOptions(body)
+	errs = append(errs, optionErrs...)
+
+	paramType := ""
+	if typeAttr, ok := body.Attributes["type"]; ok {
+		paramType, _ = literalString(typeAttr.Expr)
+	}
+
+	var defaultValue *string
+	if defaultAttr, ok := body.Attributes["default"]; ok {
+		if value, isLiteral := literalString(defaultAttr.Expr); isLiteral {
+			defaultValue = &value
+		}
+
+		// Options can also be generated with dynamic blocks, in which case we cannot know the full set of values
+		// without evaluating the configuration. List parameters encode their default as JSON, so they are skipped too.
+		hasDynamicOptions := slices.ContainsFunc(body.Blocks, func(b *hclsyntax.Block) bool {
+			return b.Type == "dynamic" && len(b.Labels) == 1 && b.Labels[0] == "option"
+		})
+		isStaticSelect := len(options) != 0 && !hasDynamicOptions && paramType != "list(string)"
+		if defaultValue != nil && isStaticSelect {
+			isOption := slices.ContainsFunc(options, func(opt parameterOption) bool {
+				return opt.value == *defaultValue
+			})
+			if !isOption {
+				errs = append(errs, addRangeToError(defaultAttr.SrcRange, xerrors.Errorf("parameter %q has default %q, which is not one of its option values", paramName, *defaultValue)))
+			}
+		}
+	}
+
+	// The provider defaults mutable to false, so an image parameter that leaves it out is just as stuck.
+	if slices.Contains(imageParams, paramName) {
+		mutableAttr, ok := body.Attributes["mutable"]
+		switch {
+		case !ok:
+			errs = append(errs, addRangeToError(param.block.DefRange(), xerrors.Errorf("parameter %q selects the workspace image, so it must set mutable = true to let workspaces move to newer images", paramName)))
+		case isLiteralFalse(mutableAttr.Expr):
+			errs = append(errs, addRangeToError(mutableAttr.SrcRange, xerrors.Errorf("parameter %q selects the workspace image, so it must be mutable to let workspaces move to newer images", paramName)))
+		}
+	}
+
+	errs = append(errs, validateCoderParameterValidationBlocks(body, paramType, defaultValue)...)
+
+	if iconAttr, ok := body.Attributes["icon"]; ok {
+		if icon, isLiteral := literalString(iconAttr.Expr); isLiteral {
+			if err := validateTerraformIcon(icon); err != nil {
+				errs = append(errs, addRangeToError(iconAttr.SrcRange, err))
+			}
+		}
+	}
+	for _, b := range body.Blocks {
+		if b.Type != "option" {
+			continue
+		}
+		iconAttr, ok := b.Body.Attributes["icon"]
+		if !ok {
+			continue
+		}
+		if icon, isLiteral := literalString(iconAttr.Expr); isLiteral {
+			if err := validateTerraformIcon(icon); err != nil {
+				errs = append(errs, addRangeToError(iconAttr.SrcRange, err))
+			}
+		}
+	}
+
+	return errs
+}
+
+func validateCoderParameters(tf coderResourceTerraform) []error {
+	params := tf.blocks("data", "coder_parameter")
+	if len(params) == 0 {
+		return nil
+	}
+
+	imageParams := collectImageParameters(tf)
+	var errs []error
+	for _, p := range params {
+		if len(p.block.Labels) != 2 {
+			errs = append(errs, addRangeToError(p.block.DefRange(), xerrors.New("coder_parameter data source must have exactly two labels")))
+			continue
+		}
+		errs = append(errs, validateCoderParameter(p, imageParams)...)
+	}
+	return errs
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func parseTestTerraform(t *testing.T, src string) coderResourceTerraform {
+	t.Helper()
+
+	tf, err := parseTerraformFile("main.tf", []byte(src))
+	if err != nil {
+		t.Fatalf("Failed to parse Terraform: %v", err)
+	}
+	return coderResourceTerraform{
+		resourceType: "templates",
+		dirPath:      ".",
+		files:        []terraformFile{tf},
+	}
+}
+
+func TestValidateCoderParameters(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		src         string
+		expectedErr string
+	}{
+		{
+			name: "valid select parameter",
+			src: `
+data "coder_parameter" "region" {
+  name    = "region"
+  default = "us"
+  icon    = "/icon/memory.svg"
+  option {
+    name  = "US"
+    value = "us"
+  }
+  option {
+    name  = "EU"
+    value = "eu"
+  }
+}`,
+		},
+		{
+			name: "default not in options",
+			src: `
+data "coder_parameter" "region" {
+  name    = "region"
+  default = "asia"
+  option {
+    name  = "US"
+    value = "us"
+  }
+}`,
+			expectedErr: `default "asia", which is not one of its option values`,
+		},
+		{
+			name: "dynamic options skip default check",
+			src: `
+data "coder_parameter" "region" {
+  name    = "region"
+  default = "asia"
+  option {
+    name  = "US"
+    value = "us"
+  }
+  dynamic "option" {
+    for_each = local.regions
+    content {
+      name  = option.value
+      value = option.key
+    }
+  }
+}`,
+		},
+		{
+			name: "duplicated option values",
+			src: `
+data "coder_parameter" "cpu" {
+  name = "cpu"
+  option {
+    name  = "2 Cores"
+    value = 2
+  }
+  option {
+    name  = "Two Cores"
+    value = "2"
+  }
+}`,
+			expectedErr: `main.tf:10": option value "2" is duplicated (first defined on line 4)`,
+		},
+		{
+			name: "immutable image parameter",
+			src: `
+data "coder_parameter" "image" {
+  name    = "image"
+  mutable = false
+}
+
+locals {
+  image = data.coder_parameter.image.value
+}
+
+resource "docker_container" "workspace" {
+  image = local.image
+}`,
+			expectedErr: `parameter "image" selects the workspace image`,
+		},
+		{
+			name: "image parameter without mutable",
+			src: `
+data "coder_parameter" "image" {
+  name = "image"
+}
+
+resource "docker_container" "workspace" {
+  image = data.coder_parameter.image.value
+}`,
+			expectedErr: `main.tf:2": parameter "image" selects the workspace image, so it must set mutable = true`,
+		},
+		{
+			name: "mutable image parameter",
+			src: `
+data "coder_parameter" "image" {
+  name    = "image"
+  mutable = true
+}
+
+resource "docker_container" "workspace" {
+  image = data.coder_parameter.image.value
+}`,
+		},
+		{
+			name: "data block without labels",
+			src: `
+data "coder_parameter" "region" {
+  name = "region"
+}
+
+data {}`,
+		},
+		{
+			name: "immutable non-image parameter",
+			src: `
+data "coder_parameter" "disk_size" {
+  name    = "disk_size"
+  mutable = false
+}
+
+resource "docker_container" "workspace" {
+  image = "codercom/enterprise-base:ubuntu"
+  name  = data.coder_parameter.disk_size.value
+}`,
+		},
+		{
+			name: "invalid validation regex",
+			src: `
+data "coder_parameter" "path" {
+  name = "path"
+  validation {
+    regex = "^(/[a-z]+$"
+  }
+}`,
+			expectedErr: "validation regex",
+		},
+		{
+			name: "default does not match validation regex",
+			src: `
+data "coder_parameter" "path" {
+  name    = "path"
+  default = "relative/path"
+  validation {
+    regex = "^/"
+  }
+}`,
+			expectedErr: `default value "relative/path" does not match validation regex`,
+		},
+		{
+			name: "missing icon",
+			src: `
+data "coder_parameter" "path" {
+  name = "path"
+  icon = "/icon/definitely-not-an-icon.svg"
+}`,
+			expectedErr: "is neither a built-in Coder icon nor a file in the top-level .icons directory",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			errs := validateCoderParameters(parseTestTerraform(t, tc.src))
+			if tc.expectedErr == "" {
+				for _, e := range errs {
+					t.Errorf("Unexpected validation error: %v", e)
+				}
+				return
+			}
+
+			found := false
+			for _, e := range errs {
+				found = found || strings.Contains(e.Error(), tc.expectedErr)
+			}
+			if !found {
+				t.Errorf("Expected error containing %q, got: %v", tc.expectedErr, errs)
+			}
+		})
+	}
+}
@@ -6,12 +6,12 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"reflect"
 	"regexp"
 	"slices"
 	"strings"
 
 	"golang.org/x/xerrors"
-	"gopkg.in/yaml.v3"
 )
 
 var (
@@ -29,24 +29,27 @@ var (
 	gfmAlertRegex = regexp.MustCompile(`^>(\s*)\[!(\w+)\](\s*)(.*)`)
 )
 
+// coderResourceFrontmatter is the YAML frontmatter of a module or template README. Fields are declared in the
+// canonical frontmatter order that the fmt command sorts keys into, and the jsonschema tags feed the published JSON
+// Schema, so keep both in sync with the validation below.
 type coderResourceFrontmatter struct {
-	Description      string   `yaml:"description"`
-	IconURL          string   `yaml:"icon"`
-	DisplayName      *string  `yaml:"display_name"`
-	Verified         *bool    `yaml:"verified"`
-	Tags             []string `yaml:"tags"`
-	OperatingSystems []string `yaml:"supported_os"`
+	DisplayName      *string  `yaml:"display_name" jsonschema_description:"Human-readable name. Must not be empty if set."`
+	Description      string   `yaml:"description" jsonschema:"required" jsonschema_description:"Short summary shown on the Registry site and in search results."`
+	IconURL          string   `yaml:"icon" jsonschema:"required" jsonschema_description:"Relative path to an icon in the top-level .icons directory, e.g. ../../../../.icons/code.svg."`
+	Verified         *bool    `yaml:"verified" jsonschema_description:"Whether the resource is verified by Coder. Only allowed for namespaces whose contributor status permits it."`
+	Tags             []string `yaml:"tags" jsonschema:"required" jsonschema_description:"Tags used by the Registry site filters. Must be URL-safe."`
+	OperatingSystems []string `yaml:"supported_os" jsonschema_description:"Operating systems the resource supports."`
 }
 
-// A slice version of the struct tags from coderResourceFrontmatter. Might be worth using reflection to generate this
-// list at runtime in the future, but this should be okay for now
-var supportedCoderResourceStructKeys = []string{
-	"description", "icon", "display_name", "verified", "tags", "supported_os",
+// deprecatedCoderResourceStructKeys lists keys that are still accepted in resource frontmatter but no longer used.
+var deprecatedCoderResourceStructKeys = []string{
 	// TODO: This is an old, officially deprecated key from the archived coder/modules repo. We can remove this once we
 	// make sure that the Registry Server is no longer checking this field.
 	"maintainer_github",
 }
 
+var supportedCoderResourceStructKeys = append(frontmatterKeys(reflect.TypeFor[coderResourceFrontmatter]()), deprecatedCoderResourceStructKeys...)
+
 // coderResourceReadme represents a README describing a Terraform resource used
 // to help create Coder workspaces. As of 2025-04-15, this encapsulates both
 // Coder Modules and Coder Templates.
@@ -174,25 +177,16 @@ func validateCoderResourceFrontmatter(resourceType string, filePath string, fm c
 }
 
 func parseCoderResourceReadme(resourceType string, rm readme) (coderResourceReadme, []error) {
-	fm, body, err := separateFrontmatter(rm.rawText)
-	if err != nil {
-		return coderResourceReadme{}, []error{xerrors.Errorf("%q: failed to parse frontmatter: %v", rm.filePath, err)}
-	}
-
-	keyErrs := validateFrontmatterYamlKeys(fm, supportedCoderResourceStructKeys)
-	if len(keyErrs) != 0 {
+	yml := coderResourceFrontmatter{}
+	body, errs := decodeFrontmatter(rm.rawText, &yml, supportedCoderResourceStructKeys)
+	if len(errs) != 0 {
 		var remapped []error
-		for _, e := range keyErrs {
+		for _, e := range errs {
 			remapped = append(remapped, addFilePathToError(rm.filePath, e))
 		}
 		return coderResourceReadme{}, remapped
 	}
 
-	yml := coderResourceFrontmatter{}
-	if err := yaml.Unmarshal([]byte(fm), &yml); err != nil {
-		return coderResourceReadme{}, []error{xerrors.Errorf("%q: failed to parse: %v", rm.filePath, err)}
-	}
-
 	return coderResourceReadme{
 		resourceType: resourceType,
 		filePath:     rm.filePath,
@@ -1,17 +1,15 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"errors"
 	"os"
 	"path"
+	"reflect"
 	"regexp"
-	"slices"
 	"strings"
 
 	"golang.org/x/xerrors"
-	"gopkg.in/yaml.v3"
 )
 
 // skillsRepoSpecRe matches the "owner/repo" or "owner/repo@ref" format used
@@ -30,30 +28,30 @@ const skillsIconPrefix = "../../../.icons/"
 // skillOverride holds per-skill presentation metadata defined in the
 // registry README. All fields are optional.
 type skillOverride struct {
-	DisplayName string   `yaml:"display_name"`
-	Description string   `yaml:"description"`
-	Icon        string   `yaml:"icon"`
-	Tags        []string `yaml:"tags"`
+	DisplayName string   `yaml:"display_name" jsonschema_description:"Human-readable name. Defaults to the skill slug."`
+	Description string   `yaml:"description" jsonschema_description:"Short summary shown on the Registry site."`
+	Icon        string   `yaml:"icon" jsonschema_description:"Relative path to an icon in the top-level .icons directory. Defaults to the README icon."`
+	Tags        []string `yaml:"tags" jsonschema_description:"Tags used by the Registry site filters."`
 }
 
 // skillSource is one entry in the sources list, describing a single source
 // repo and optional per-skill overrides.
 type skillSource struct {
-	Repo   string                   `yaml:"repo"`
-	Skills map[string]skillOverride `yaml:"skills"`
+	Repo   string                   `yaml:"repo" jsonschema:"required" jsonschema_description:"Source repo in the form owner/repo or owner/repo@ref."`
+	Skills map[string]skillOverride `yaml:"skills" jsonschema_description:"Skills published from the repo, keyed by slug."`
 }
 
 // coderSkillsFrontmatter is the YAML frontmatter schema for
 // registry/<namespace>/skills/README.md.
 type coderSkillsFrontmatter struct {
-	Icon    string        `yaml:"icon"`
-	Sources []skillSource `yaml:"sources"`
+	Icon    string        `yaml:"icon" jsonschema_description:"Relative path to an icon in the top-level .icons directory, e.g. ../../../.icons/coder.svg."`
+	Sources []skillSource `yaml:"sources" jsonschema:"required" jsonschema_description:"Repos that skills are published from."`
 }
 
 // supportedSkillsTopLevelKeys lists the keys allowed at the root of the
 // skills README frontmatter. Nested keys under sources are validated
 // separately because the typed unmarshal handles them.
-var supportedSkillsTopLevelKeys = []string{"icon", "sources"}
+var supportedSkillsTopLevelKeys = frontmatterKeys(reflect.TypeFor[coderSkillsFrontmatter]())
 
 // coderSkillsReadme represents a parsed skills README file.
 type coderSkillsReadme struct {
@@ -62,48 +60,6 @@ type coderSkillsReadme struct {
 	frontmatter coderSkillsFrontmatter
 }
 
-// separateSkillsFrontmatter is like separateFrontmatter but preserves
-// indentation in the frontmatter block. The skills README uses nested YAML
-// (per-skill metadata under each source), which the indentation-trimming
-// behavior of the shared separateFrontmatter helper destroys.
-func separateSkillsFrontmatter(readmeText string) (frontmatter string, body string, err error) {
-	if readmeText == "" {
-		return "", "", xerrors.New("README is empty")
-	}
-
-	const fence = "---"
-	var fmBuilder strings.Builder
-	var bodyBuilder strings.Builder
-	fenceCount := 0
-
-	lineScanner := bufio.NewScanner(strings.NewReader(strings.TrimSpace(readmeText)))
-	for lineScanner.Scan() {
-		nextLine := lineScanner.Text()
-		if fenceCount < 2 && strings.TrimSpace(nextLine) == fence {
-			fenceCount++
-			continue
-		}
-		if fenceCount == 0 {
-			break
-		}
-		if fenceCount >= 2 {
-			bodyBuilder.WriteString(nextLine)
-			bodyBuilder.WriteString("\n")
-		} else {
-			fmBuilder.WriteString(nextLine)
-			fmBuilder.WriteString("\n")
-		}
-	}
-	if fenceCount < 2 {
-		return "", "", xerrors.New("README does not have two sets of frontmatter fences")
-	}
-	if strings.TrimSpace(fmBuilder.String()) == "" {
-		return "", "", xerrors.New("readme has frontmatter fences but no frontmatter content")
-	}
-
-	return fmBuilder.String(), strings.TrimSpace(bodyBuilder.String()), nil
-}
-
 // isPermittedSkillsIconURL validates that an icon URL references the
 // repo-level .icons directory using the 3-deep prefix appropriate for
 // skills READMEs, and that the file exists on disk.
@@ -142,24 +98,6 @@ func validateSkillsIconURL(iconURL string, filePath string) []error {
 	return errs
 }
 
-// validateSkillsTopLevelKeys parses the (indentation-preserved) frontmatter
-// as a YAML map and verifies that every top-level key is in the supported
-// set. This catches typos like "source:" vs "sources:".
-func validateSkillsTopLevelKeys(fm string) []error {
-	var rawKeys map[string]any
-	if err := yaml.Unmarshal([]byte(fm), &rawKeys); err != nil {
-		return []error{xerrors.Errorf("failed to parse frontmatter as YAML map: %v", err)}
-	}
-
-	var errs []error
-	for key := range rawKeys {
-		if !slices.Contains(supportedSkillsTopLevelKeys, key) {
-			errs = append(errs, xerrors.Errorf("detected unknown top-level key %q (allowed: %s)", key, strings.Join(supportedSkillsTopLevelKeys, ", ")))
-		}
-	}
-	return errs
-}
-
 func validateSkillsSources(sources []skillSource, filePath string) []error {
 	if len(sources) == 0 {
 		return []error{xerrors.New("at least one source repo is required under 'sources'")}
@@ -212,25 +150,16 @@ func validateCoderSkillsFrontmatter(filePath string, fm coderSkillsFrontmatter)
 }
 
 func parseCoderSkillsReadme(rm readme) (coderSkillsReadme, []error) {
-	fm, body, err := separateSkillsFrontmatter(rm.rawText)
-	if err != nil {
-		return coderSkillsReadme{}, []error{xerrors.Errorf("%q: failed to parse frontmatter: %v", rm.filePath, err)}
-	}
-
-	keyErrs := validateSkillsTopLevelKeys(fm)
-	if len(keyErrs) != 0 {
+	yml := coderSkillsFrontmatter{}
+	body, errs := decodeFrontmatter(rm.rawText, &yml, supportedSkillsTopLevelKeys)
+	if len(errs) != 0 {
 		var remapped []error
-		for _, e := range keyErrs {
+		for _, e := range errs {
 			remapped = append(remapped, addFilePathToError(rm.filePath, e))
 		}
 		return coderSkillsReadme{}, remapped
 	}
 
-	yml := coderSkillsFrontmatter{}
-	if err := yaml.Unmarshal([]byte(fm), &yml); err != nil {
-		return coderSkillsReadme{}, []error{xerrors.Errorf("%q: failed to parse: %v", rm.filePath, err)}
-	}
-
 	return coderSkillsReadme{
 		filePath:    rm.filePath,
 		body:        body,
@@ -265,7 +194,20 @@ func validateAllCoderSkillsReadmes(readmes []coderSkillsReadme) error {
 		if len(errs) > 0 {
 			validationErrs = append(validationErrs, errs...)
 		}
+		for _, err := range validateReadmeBody(rm.body) {
+			validationErrs = append(validationErrs, addFilePathToError(rm.filePath, err))
+		}
+		for _, err := range validateAvailableSkillsTable(rm) {
+			validationErrs = append(validationErrs, addFilePathToError(rm.filePath, err))
+		}
 	}
+
+	policy, err := loadSkillsRefPolicy()
+	if err != nil {
+		return err
+	}
+	validationErrs = append(validationErrs, validateSkillsRefPolicy(policy, readmes)...)
+	validationErrs = append(validationErrs, validateSkillsSlugCollisions(readmes)...)
 	if len(validationErrs) != 0 {
 		return validationPhaseError{
 			phase:  validationPhaseReadme,
@@ -334,6 +276,10 @@ func validateAllCoderSkills() error {
 		return err
 	}
 
+	if err := validateSkillsIndex(readmes); err != nil {
+		return err
+	}
+
 	logger.Info(context.Background(), "processed all skills README files", "num_files", len(readmes))
 	return nil
 }
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// command is a subcommand of the readmevalidation binary. Running the binary without a subcommand validates the
+// whole registry, which is what CI does.
+type command struct {
+	name        string
+	description string
+	run         func(args []string) error
+}
+
+var commands = []command{
+	{
+		name:        "codeowners",
+		description: "Regenerate the root CODEOWNERS file from the contributor profiles",
+		run:         runCodeownersCommand,
+	},
+	{
+		name:        "skill",
+		description: "Validate local agent skill directories against the agent skills specification",
+		run:         runSkillCommand,
+	},
+	{
+		name:        "skills-index",
+		description: "Regenerate the agent skills well-known discovery index from the skills READMEs",
+		run:         runSkillsIndexCommand,
+	},
+	{
+		name:        "skills-table",
+		description: "Regenerate the Available Skills table of every skills README from its frontmatter",
+		run:         runSkillsTableCommand,
+	},
+	{
+		name:        "fmt",
+		description: "Rewrite README frontmatter into canonical key order and style (flags: --check)",
+		run:         runFmtCommand,
+	},
+	{
+		name:        "mv",
+		description: "Rename a namespace or move a module, rewriting references and recording an alias for the old path",
+		run:         runMvCommand,
+	},
+	{
+		name:        "redirects",
+		description: "Regenerate the registry redirects file from the namespace and module aliases",
+		run:         runRedirectsCommand,
+	},
+	{
+		name:        "schema",
+		description: "Regenerate the JSON Schemas for README frontmatter, or print the schema of one kind",
+		run:         runSchemaCommand,
+	},
+	{
+		name:        "mcp",
+		description: "Serve the registry's modules and templates to AI agents as an MCP server over stdio",
+		run:         runMCPCommand,
+	},
+	{
+		name:        "lsp",
+		description: "Serve diagnostics, completions, and quick fixes for registry READMEs as a language server over stdio",
+		run:         runLSPCommand,
+	},
+	{
+		name:        "duplicates",
+		description: "Report modules and templates that look like duplicates across namespaces (flags: --threshold, --type)",
+		run:         runDuplicatesCommand,
+	},
+	{
+		name:        "search",
+		description: "Search modules, templates, and skills (flags: --type, --os, --tag, --verified, --limit)",
+		run:         runSearchCommand,
+	},
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: readmevalidation [command] [flags]")
+	fmt.Fprintln(os.Stderr, "\nWithout a command, validates every README and asset in the registry.")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", c.name, c.description)
+	}
+}
+
+// runCommand runs the named subcommand and exits the process with an appropriate status code.
+func runCommand(name string, args []string) {
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		if err := c.run(args); err != nil {
+			logger.Error(context.Background(), "command failed", "command", name, "error", err.Error())
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	printUsage()
+	os.Exit(2)
+}
@@ -1,33 +1,54 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"image"
+	_ "image/gif" // Registers the GIF decoder for image.DecodeConfig.
 	"net/url"
 	"os"
 	"path"
+	"reflect"
 	"slices"
 	"strings"
 
 	"golang.org/x/xerrors"
-	"gopkg.in/yaml.v3"
+)
+
+const (
+	// maxAvatarFileBytes is the largest avatar image allowed in the repo. Avatars are shown at small sizes, so anything
+	// larger is wasted bandwidth for every visitor of the Registry site.
+	maxAvatarFileBytes = 1024 * 1024
+
+	// minAvatarSize is the smallest width and height allowed for raster avatars.
+	minAvatarSize = 96
 )
 
 var validContributorStatuses = []string{"official", "partner", "community"}
 
+// grandfatheredAvatarNamespaces lists the namespaces whose avatar images were added before the image checks existed
+// and do not meet them. Their avatars still have to exist, but are exempt from the content checks until they are
+// replaced. Do not add new entries.
+var grandfatheredAvatarNamespaces = []string{
+	"joergklein",
+	"nboyers",
+}
+
+// contributorProfileFrontmatter is the YAML frontmatter of a namespace's contributor README. Fields are declared in
+// the canonical frontmatter order that the fmt command sorts keys into, and the jsonschema tags feed the published
+// JSON Schema, so keep both in sync with the validation below.
 type contributorProfileFrontmatter struct {
-	DisplayName       string  `yaml:"display_name"`
-	Bio               string  `yaml:"bio"`
-	ContributorStatus string  `yaml:"status"`
-	AvatarURL         *string `yaml:"avatar"`
-	GithubUsername    *string `yaml:"github"`
-	LinkedinURL       *string `yaml:"linkedin"`
-	WebsiteURL        *string `yaml:"website"`
-	SupportEmail      *string `yaml:"support_email"`
+	DisplayName       string  `yaml:"display_name" jsonschema:"required" jsonschema_description:"Name shown for the namespace on the Registry site."`
+	Bio               string  `yaml:"bio" jsonschema_description:"Short description of the contributor."`
+	AvatarURL         *string `yaml:"avatar" jsonschema_description:"Relative path to an avatar image in the namespace's .images directory."`
+	GithubUsername    *string `yaml:"github" jsonschema_description:"GitHub username of the namespace owner, with its canonical casing."`
+	LinkedinURL       *string `yaml:"linkedin" jsonschema_description:"URL of a LinkedIn profile."`
+	WebsiteURL        *string `yaml:"website" jsonschema_description:"URL of a personal or company website."`
+	SupportEmail      *string `yaml:"support_email" jsonschema_description:"Email address users can contact for support."`
+	ContributorStatus string  `yaml:"status" jsonschema:"required" jsonschema_description:"Relationship to Coder. Determines which frontmatter fields the namespace may use."`
 }
 
-// A slice version of the struct tags from contributorProfileFrontmatter. Might be worth using reflection to generate
-// this list at runtime in the future, but this should be okay for now
-var supportedContributorProfileStructKeys = []string{"display_name", "bio", "status", "avatar", "linkedin", "github", "website", "support_email"}
+var supportedContributorProfileStructKeys = frontmatterKeys(reflect.TypeFor[contributorProfileFrontmatter]())
 
 type contributorProfileReadme struct {
 	frontmatter contributorProfileFrontmatter
@@ -68,6 +89,9 @@ func validateGithubUsername(username *string) error {
 	if name != trimmed {
 		return xerrors.Errorf("username %q has extra whitespace", trimmed)
 	}
+	if !isValidGithubLogin(name) {
+		return xerrors.Errorf("username %q is not a valid GitHub login; logins are at most %d characters of letters, digits, and single hyphens, and cannot start or end with a hyphen", name, maxGithubLoginLength)
+	}
 	return nil
 }
 
@@ -195,25 +219,15 @@ func validateContributorReadme(rm contributorProfileReadme) []error {
 }
 
 func parseContributorProfile(rm readme) (contributorProfileReadme, []error) {
-	fm, _, err := separateFrontmatter(rm.rawText)
-	if err != nil {
-		return contributorProfileReadme{}, []error{xerrors.Errorf("%q: failed to parse frontmatter: %v", rm.filePath, err)}
-	}
-
-	keyErrs := validateFrontmatterYamlKeys(fm, supportedContributorProfileStructKeys)
-	if len(keyErrs) != 0 {
+	yml := contributorProfileFrontmatter{}
+	if _, errs := decodeFrontmatter(rm.rawText, &yml, supportedContributorProfileStructKeys); len(errs) != 0 {
 		var remapped []error
-		for _, e := range keyErrs {
+		for _, e := range errs {
 			remapped = append(remapped, addFilePathToError(rm.filePath, e))
 		}
 		return contributorProfileReadme{}, remapped
 	}
 
-	yml := contributorProfileFrontmatter{}
-	if err := yaml.Unmarshal([]byte(fm), &yml); err != nil {
-		return contributorProfileReadme{}, []error{xerrors.Errorf("%q: failed to parse: %v", rm.filePath, err)}
-	}
-
 	return contributorProfileReadme{
 		filePath:    rm.filePath,
 		frontmatter: yml,
@@ -298,6 +312,40 @@ func aggregateContributorReadmeFiles() ([]readme, error) {
 	return allReadmeFiles, nil
 }
 
+// validateContributorAvatarImage validates the contents of an avatar image stored in the repo. Avatars are rendered
+// as circles on the Registry site, so they must be square, and raster avatars must be large enough to stay sharp.
+func validateContributorAvatarImage(fileName string, content []byte) []error {
+	if len(content) > maxAvatarFileBytes {
+		return []error{xerrors.Errorf("avatar file is %d KB, which exceeds the %d KB limit", len(content)/1024, maxAvatarFileBytes/1024)}
+	}
+
+	if strings.ToLower(path.Ext(fileName)) == ".svg" {
+		errs := validateSvgIcon(fileName, content)
+		if len(errs) != 0 {
+			return errs
+		}
+		width, height, ok := svgViewBoxSize(content)
+		if ok && width != height {
+			errs = append(errs, xerrors.Errorf("SVG avatar must be square (found viewBox of %gx%g)", width, height))
+		}
+		return errs
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return []error{xerrors.Errorf("failed to decode avatar image: %v", err)}
+	}
+
+	var errs []error
+	if cfg.Width != cfg.Height {
+		errs = append(errs, xerrors.Errorf("%s avatar must be square (found %dx%d)", format, cfg.Width, cfg.Height))
+	}
+	if cfg.Width < minAvatarSize || cfg.Height < minAvatarSize {
+		errs = append(errs, xerrors.Errorf("%s avatar must be at least %dx%d (found %dx%d)", format, minAvatarSize, minAvatarSize, cfg.Width, cfg.Height))
+	}
+	return errs
+}
+
 func validateContributorRelativeURLs(contributors map[string]contributorProfileReadme) error {
 	// This function only validates relative avatar URLs for now, but it can be beefed up to validate more in the future.
 	var errs []error
@@ -309,20 +357,29 @@ func validateContributorRelativeURLs(contributors map[string]contributorProfileR
 			continue
 		}
 
-		if !strings.HasPrefix(*con.frontmatter.AvatarURL, ".") || !strings.HasPrefix(*con.frontmatter.AvatarURL, "/") {
+		avatarURL := *con.frontmatter.AvatarURL
+		if strings.HasPrefix(avatarURL, "http://") || strings.HasPrefix(avatarURL, "https://") {
 			continue
 		}
 
-		isAvatarInApprovedSpot := strings.HasPrefix(*con.frontmatter.AvatarURL, "./.images/") ||
-			strings.HasPrefix(*con.frontmatter.AvatarURL, ".images/")
-		if !isAvatarInApprovedSpot {
-			errs = append(errs, xerrors.Errorf("%q: relative avatar URLs cannot be placed outside a user's namespaced directory", con.filePath))
+		namespaceDir := path.Dir(con.filePath)
+		imagesDir := path.Join(namespaceDir, ".images")
+		resolvedPath := path.Join(namespaceDir, avatarURL)
+		if !strings.HasPrefix(resolvedPath, imagesDir+"/") {
+			errs = append(errs, xerrors.Errorf("%q: relative avatar URL %q must point to a file inside the namespace's .images directory", con.filePath, avatarURL))
 			continue
 		}
 
-		absolutePath := strings.TrimSuffix(con.filePath, "README.md") + *con.frontmatter.AvatarURL
-		if _, err := os.ReadFile(absolutePath); err != nil {
-			errs = append(errs, xerrors.Errorf("%q: relative avatar path %q does not point to image in file system", con.filePath, absolutePath))
+		content, err := os.ReadFile(resolvedPath)
+		if err != nil {
+			errs = append(errs, xerrors.Errorf("%q: relative avatar path %q does not point to image in file system", con.filePath, resolvedPath))
+			continue
+		}
+		if slices.Contains(grandfatheredAvatarNamespaces, con.namespace) {
+			continue
+		}
+		for _, err := range validateContributorAvatarImage(resolvedPath, content) {
+			errs = append(errs, addFilePathToError(resolvedPath, err))
 		}
 	}
 
@@ -353,6 +410,11 @@ func validateAllContributorFiles() error {
 	}
 	logger.Info(context.Background(), "all relative URLs for READMEs are valid")
 
+	if err := validateCodeowners(contributors); err != nil {
+		return err
+	}
+	logger.Info(context.Background(), "CODEOWNERS is up to date with the contributor profiles")
+
 	logger.Info(context.Background(), "processed all READMEs in directory", "dir", rootRegistryPath)
 	return nil
 }
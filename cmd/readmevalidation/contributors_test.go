@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateContributorAvatarImage(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		fileName    string
+		content     []byte
+		expectedErr string
+	}{
+		{
+			name:     "valid PNG",
+			fileName: "avatar.png",
+			content:  encodeTestPNG(t, 460, 460),
+		},
+		{
+			name:        "non-square PNG",
+			fileName:    "avatar.png",
+			content:     encodeTestPNG(t, 460, 200),
+			expectedErr: "must be square",
+		},
+		{
+			name:        "undersized PNG",
+			fileName:    "avatar.png",
+			content:     encodeTestPNG(t, 48, 48),
+			expectedErr: "must be at least 96x96",
+		},
+		{
+			name:        "oversized file",
+			fileName:    "avatar.png",
+			content:     make([]byte, maxAvatarFileBytes+1),
+			expectedErr: "exceeds the 1024 KB limit",
+		},
+		{
+			name:        "undecodable image",
+			fileName:    "avatar.jpeg",
+			content:     []byte("not an image"),
+			expectedErr: "failed to decode avatar image",
+		},
+		{
+			name:     "valid SVG",
+			fileName: "avatar.svg",
+			content:  []byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 160 160"></svg>`),
+		},
+		{
+			name:        "non-square SVG",
+			fileName:    "avatar.svg",
+			content:     []byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 160 80"></svg>`),
+			expectedErr: "SVG avatar must be square",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			errs := validateContributorAvatarImage(tc.fileName, tc.content)
+			if tc.expectedErr == "" {
+				for _, e := range errs {
+					t.Errorf("Unexpected validation error: %v", e)
+				}
+				return
+			}
+
+			found := false
+			for _, e := range errs {
+				found = found || strings.Contains(e.Error(), tc.expectedErr)
+			}
+			if !found {
+				t.Errorf("Expected error containing %q, got: %v", tc.expectedErr, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,512 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"golang.org/x/xerrors"
+)
+
+// duplicateFeature is one aspect of a module or template that is compared when looking for duplicates.
+type duplicateFeature string
+
+const (
+	duplicateFeatureName        duplicateFeature = "name"
+	duplicateFeatureDescription duplicateFeature = "description"
+	duplicateFeatureTags        duplicateFeature = "tags"
+	duplicateFeatureResources   duplicateFeature = "resources"
+	duplicateFeatureModules     duplicateFeature = "modules"
+	duplicateFeatureApps        duplicateFeature = "apps"
+	duplicateFeatureScripts     duplicateFeature = "scripts"
+)
+
+// duplicateFeatures lists the compared features in the order they are reported.
+var duplicateFeatures = []duplicateFeature{
+	duplicateFeatureName,
+	duplicateFeatureDescription,
+	duplicateFeatureTags,
+	duplicateFeatureResources,
+	duplicateFeatureModules,
+	duplicateFeatureApps,
+	duplicateFeatureScripts,
+}
+
+// duplicateFeatureWeights weighs the similarity of each feature in the overall score. Names and module dependencies
+// are the strongest signals: two modules that are called alike or build on the same modules almost always do the
+// same job, while most modules share a handful of tags and coder_* resources.
+var duplicateFeatureWeights = map[duplicateFeature]float64{
+	duplicateFeatureName:        0.25,
+	duplicateFeatureDescription: 0.15,
+	duplicateFeatureTags:        0.1,
+	duplicateFeatureResources:   0.1,
+	duplicateFeatureModules:     0.25,
+	duplicateFeatureApps:        0.05,
+	duplicateFeatureScripts:     0.1,
+}
+
+const (
+	// defaultDuplicateThreshold is the score above which two resources are reported as likely duplicates.
+	defaultDuplicateThreshold = 0.5
+
+	// minDuplicateScriptLineLength is the shortest normalized script line that is compared. Shorter lines like "fi" or
+	// "done" are shared by every shell script.
+	minDuplicateScriptLineLength = 8
+
+	// minDuplicatePrefixLength is the shortest term that matches the longer terms it is a prefix of, so that "plugin"
+	// matches "plugins" and "code" matches "codex", but "ai" doesn't match every word starting with it.
+	minDuplicatePrefixLength = 4
+)
+
+// duplicatePrefixFeatures are the features made of words, where a term also matches the terms it is a prefix of.
+var duplicatePrefixFeatures = []duplicateFeature{duplicateFeatureName, duplicateFeatureDescription, duplicateFeatureApps}
+
+// duplicateModuleSourceRe matches a registry module source anywhere in a README or Terraform file, capturing its
+// "<namespace>/<module>" path.
+var duplicateModuleSourceRe = regexp.MustCompile(`registry\.coder\.com/([a-zA-Z0-9_-]+/[a-zA-Z0-9_-]+)/coder`)
+
+// appURLSchemeRe matches the scheme of a URL in Terraform source, e.g. "jetbrains-gateway" in
+// "jetbrains-gateway://connect".
+var appURLSchemeRe = regexp.MustCompile(`"([a-z][a-z0-9+.-]*)://`)
+
+// duplicateStopwords are words that say nothing about what a resource does, because nearly every name or description
+// in the registry uses them.
+var duplicateStopwords = []string{
+	"a", "an", "and", "coder", "for", "in", "into", "module", "of", "on", "or", "template", "the", "to", "using",
+	"with", "workspace", "workspaces", "your",
+}
+
+// duplicateFingerprint holds the normalized feature sets of a single module or template.
+type duplicateFingerprint struct {
+	resourceType string
+	id           string
+	filePath     string
+	features     map[duplicateFeature][]string
+}
+
+// duplicateMatch is a pair of resources that look alike, with their overall and per-feature similarity.
+type duplicateMatch struct {
+	a, b         duplicateFingerprint
+	score        float64
+	similarities map[duplicateFeature]float64
+}
+
+// duplicateTerms tokenizes text and drops stopwords, returning a sorted set of terms.
+func duplicateTerms(texts ...string) []string {
+	var terms []string
+	for _, text := range texts {
+		for _, term := range tokenizeSearchText(text) {
+			if !slices.Contains(duplicateStopwords, term) {
+				terms = append(terms, term)
+			}
+		}
+	}
+	slices.Sort(terms)
+	return slices.Compact(terms)
+}
+
+// normalizedScriptLines returns the set of meaningful lines in a script, with whitespace trimmed and blank lines,
+// comments, and very short lines removed, so that reformatted copies of a script still match.
+func normalizedScriptLines(script string) []string {
+	var lines []string
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if len(line) < minDuplicateScriptLineLength || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// terraformResourceSet returns the resource types and data source types a configuration uses.
+func terraformResourceSet(tf coderResourceTerraform) []string {
+	var set []string
+	for _, f := range tf.files {
+		for _, b := range f.body.Blocks {
+			switch {
+			case b.Type == "resource" && len(b.Labels) == 2:
+				set = append(set, b.Labels[0])
+			case b.Type == "data" && len(b.Labels) == 2:
+				set = append(set, "data."+b.Labels[0])
+			}
+		}
+	}
+	slices.Sort(set)
+	return slices.Compact(set)
+}
+
+// registryModuleDependencies returns the "<namespace>/<module>" path of every other registry module that a resource's
+// Terraform uses or its README shows in an example. Companion modules are shown next to the module they extend, and
+// modules that do the same job tend to build on the same helper modules.
+func registryModuleDependencies(r catalogResource) []string {
+	var deps []string
+	texts := []string{r.readme.body}
+	for _, f := range r.terraform.files {
+		texts = append(texts, string(f.src))
+	}
+	for _, text := range texts {
+		for _, match := range duplicateModuleSourceRe.FindAllStringSubmatch(text, -1) {
+			if match[1] != r.id() {
+				deps = append(deps, match[1])
+			}
+		}
+	}
+	slices.Sort(deps)
+	return slices.Compact(deps)
+}
+
+// terraformAppTerms returns the terms that identify the apps a configuration adds to the dashboard: the name and slug
+// of every coder_app, and the scheme of app URLs that open a desktop application, like "jetbrains-gateway".
+func terraformAppTerms(tf coderResourceTerraform) []string {
+	defaults := map[string]string{}
+	for _, v := range tf.variables() {
+		var value string
+		if json.Unmarshal(v.Default, &value) == nil {
+			defaults[v.Name] = value
+		}
+	}
+
+	var texts []string
+	for _, f := range tf.files {
+		for _, b := range f.body.Blocks {
+			if b.Type != "resource" || len(b.Labels) != 2 || b.Labels[0] != "coder_app" {
+				continue
+			}
+			texts = append(texts, b.Labels[1])
+			if attr, ok := b.Body.Attributes["slug"]; ok {
+				texts = append(texts, appSlugText(attr.Expr, defaults))
+			}
+			if attr, ok := b.Body.Attributes["url"]; ok {
+				for _, match := range appURLSchemeRe.FindAllStringSubmatch(f.expressionSource(attr.Expr), -1) {
+					if match[1] != "http" && match[1] != "https" {
+						texts = append(texts, match[1])
+					}
+				}
+			}
+		}
+	}
+	return duplicateTerms(texts...)
+}
+
+// appSlugText returns the known text of a coder_app slug: its value when it is a literal, the literal parts of a
+// template like "jetbrains-${each.key}", or the default of the variable it is set to.
+func appSlugText(expr hclsyntax.Expression, defaults map[string]string) string {
+	switch e := expr.(type) {
+	case *hclsyntax.TemplateExpr:
+		var parts []string
+		for _, part := range e.Parts {
+			if text, ok := literalString(part); ok {
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, " ")
+	case *hclsyntax.ScopeTraversalExpr:
+		if names := referencedNames(expr, "var"); len(names) == 1 {
+			return defaults[names[0]]
+		}
+	}
+	text, _ := literalString(expr)
+	return text
+}
+
+// terraformScriptLines returns the normalized lines of every script a configuration runs, both the files it reads
+// and the scripts written inline in coder_script and coder_agent blocks.
+func terraformScriptLines(tf coderResourceTerraform) []string {
+	var lines []string
+	for _, filePath := range tf.readFilePaths() {
+		content, err := os.ReadFile(filePath)
+		if err != nil || classifyScript(filePath, string(content)) == "" {
+			continue
+		}
+		lines = append(lines, normalizedScriptLines(string(content))...)
+	}
+	for _, f := range tf.files {
+		for _, b := range f.body.Blocks {
+			if b.Type != "resource" || len(b.Labels) != 2 {
+				continue
+			}
+			attrName := map[string]string{"coder_script": "script", "coder_agent": "startup_script"}[b.Labels[0]]
+			attr, ok := b.Body.Attributes[attrName]
+			if !ok {
+				continue
+			}
+			// Only inline scripts are compared here; calls to file and templatefile are covered above.
+			if _, ok := attr.Expr.(*hclsyntax.TemplateExpr); ok {
+				lines = append(lines, normalizedScriptLines(f.expressionSource(attr.Expr))...)
+			}
+		}
+	}
+	slices.Sort(lines)
+	return slices.Compact(lines)
+}
+
+func newDuplicateFingerprint(r catalogResource) duplicateFingerprint {
+	fm := r.readme.frontmatter
+	displayName := ""
+	if fm.DisplayName != nil {
+		displayName = *fm.DisplayName
+	}
+	tags := slices.Clone(fm.Tags)
+	slices.Sort(tags)
+	// Templates share the same few IDE modules regardless of what they provision, so module dependencies only say
+	// something about modules.
+	var modules []string
+	if r.resourceType == "modules" {
+		modules = registryModuleDependencies(r)
+	}
+	return duplicateFingerprint{
+		resourceType: r.resourceType,
+		id:           r.id(),
+		filePath:     r.readme.filePath,
+		features: map[duplicateFeature][]string{
+			duplicateFeatureName:        duplicateTerms(displayName, r.name),
+			duplicateFeatureDescription: duplicateTerms(fm.Description),
+			duplicateFeatureTags:        slices.Compact(tags),
+			duplicateFeatureResources:   terraformResourceSet(r.terraform),
+			duplicateFeatureModules:     modules,
+			duplicateFeatureApps:        terraformAppTerms(r.terraform),
+			duplicateFeatureScripts:     terraformScriptLines(r.terraform),
+		},
+	}
+}
+
+// jaccardSimilarity returns the size of the intersection of two sorted sets divided by the size of their union.
+func jaccardSimilarity(a, b []string) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for _, item := range a {
+		if _, found := slices.BinarySearch(b, item); found {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// prefixSimilarity is jaccardSimilarity for sets of words, where two terms also match when the shorter one is a
+// prefix of the other. Every term is matched at most once, and exact matches are made first.
+func prefixSimilarity(a, b []string) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	matchedA := make([]bool, len(a))
+	matchedB := make([]bool, len(b))
+	shared := 0
+	for i, term := range a {
+		if j, found := slices.BinarySearch(b, term); found {
+			matchedA[i], matchedB[j] = true, true
+			shared++
+		}
+	}
+	for i, term := range a {
+		if matchedA[i] {
+			continue
+		}
+		for j, other := range b {
+			if matchedB[j] || min(len(term), len(other)) < minDuplicatePrefixLength {
+				continue
+			}
+			if strings.HasPrefix(term, other) || strings.HasPrefix(other, term) {
+				matchedB[j] = true
+				shared++
+				break
+			}
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// isExtendedName reports whether one of two resource names is the other followed by a hyphenated suffix.
+func isExtendedName(a, b string) bool {
+	return strings.HasPrefix(a, b+"-") || strings.HasPrefix(b, a+"-")
+}
+
+// compareFingerprints scores how alike two resources are, from 0 to 1. Features that neither resource has, like
+// scripts for two templates that don't run any, are left out of the score instead of counting as a mismatch. The
+// names of two modules match fully when one extends the other, and so do their dependencies when one uses the other.
+func compareFingerprints(a, b duplicateFingerprint) duplicateMatch {
+	match := duplicateMatch{a: a, b: b, similarities: map[duplicateFeature]float64{}}
+	totalWeight := 0.0
+	for _, feature := range duplicateFeatures {
+		if len(a.features[feature]) == 0 && len(b.features[feature]) == 0 {
+			continue
+		}
+		similarity := jaccardSimilarity(a.features[feature], b.features[feature])
+		switch {
+		case feature == duplicateFeatureName && a.resourceType == "modules" && isExtendedName(path.Base(a.id), path.Base(b.id)):
+			// A module named after another one plus a suffix, like "jetbrains-gateway" for "jetbrains", builds on
+			// or competes with it, however differently their display names are worded.
+			similarity = 1
+		case slices.Contains(duplicatePrefixFeatures, feature):
+			similarity = prefixSimilarity(a.features[feature], b.features[feature])
+		case feature == duplicateFeatureModules &&
+			(slices.Contains(a.features[feature], b.id) || slices.Contains(b.features[feature], a.id)):
+			similarity = 1
+		}
+		match.similarities[feature] = similarity
+		match.score += similarity * duplicateFeatureWeights[feature]
+		totalWeight += duplicateFeatureWeights[feature]
+	}
+	if totalWeight > 0 {
+		match.score /= totalWeight
+	}
+	return match
+}
+
+// findDuplicates compares every pair of resources of the same type, across all namespaces, and returns the pairs
+// scoring at least the threshold, most similar first. When include is set, only pairs with at least one resource it
+// matches are compared.
+func findDuplicates(fingerprints []duplicateFingerprint, threshold float64, include func(duplicateFingerprint) bool) []duplicateMatch {
+	var matches []duplicateMatch
+	for i, a := range fingerprints {
+		for _, b := range fingerprints[i+1:] {
+			if a.resourceType != b.resourceType {
+				continue
+			}
+			if include != nil && !include(a) && !include(b) {
+				continue
+			}
+			if match := compareFingerprints(a, b); match.score >= threshold {
+				matches = append(matches, match)
+			}
+		}
+	}
+	slices.SortStableFunc(matches, func(x, y duplicateMatch) int {
+		if x.score != y.score {
+			if x.score > y.score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(x.a.resourceType+x.a.id+x.b.id, y.a.resourceType+y.a.id+y.b.id)
+	})
+	return matches
+}
+
+// breakdown describes the similarity of every compared feature, e.g. "name 100%, scripts 40%".
+func (m duplicateMatch) breakdown() string {
+	var parts []string
+	for _, feature := range duplicateFeatures {
+		if similarity, ok := m.similarities[feature]; ok {
+			parts = append(parts, fmt.Sprintf("%s %.0f%%", feature, similarity*100))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func printDuplicateReport(out io.Writer, matches []duplicateMatch) {
+	for _, m := range matches {
+		fmt.Fprintf(out, "%3.0f%%  %-9s %s <-> %s\n", m.score*100, strings.TrimSuffix(m.a.resourceType, "s"), m.a.id, m.b.id)
+		fmt.Fprintf(out, "      %s\n", m.breakdown())
+	}
+}
+
+func loadDuplicateFingerprints() ([]duplicateFingerprint, error) {
+	catalog, err := loadCatalog()
+	if err != nil {
+		return nil, err
+	}
+	fingerprints := make([]duplicateFingerprint, 0, len(catalog))
+	for _, r := range catalog {
+		fingerprints = append(fingerprints, newDuplicateFingerprint(r))
+	}
+	return fingerprints, nil
+}
+
+func runDuplicatesCommand(args []string) error {
+	flags := flag.NewFlagSet("duplicates", flag.ContinueOnError)
+	threshold := flags.Float64("threshold", defaultDuplicateThreshold, "Minimum similarity score, from 0 to 1, of the reported pairs")
+	kind := flags.String("type", "", "Only compare resources of this type (module or template)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *kind != "" && *kind != "module" && *kind != "template" {
+		return xerrors.Errorf("unknown type %q (expected module or template)", *kind)
+	}
+
+	fingerprints, err := loadDuplicateFingerprints()
+	if err != nil {
+		return err
+	}
+	if *kind != "" {
+		fingerprints = slices.DeleteFunc(fingerprints, func(f duplicateFingerprint) bool {
+			return f.resourceType != *kind+"s"
+		})
+	}
+	matches := findDuplicates(fingerprints, *threshold, nil)
+	if len(matches) == 0 {
+		fmt.Fprintln(os.Stderr, "No likely duplicates.")
+		return nil
+	}
+	printDuplicateReport(os.Stdout, matches)
+	return nil
+}
+
+// registryReadmesAtRef returns the path of every README under the registry directory at a git ref.
+func registryReadmesAtRef(ref string) ([]string, error) {
+	out, err := exec.Command("git", "ls-tree", "-r", "--name-only", "--end-of-options", ref, path.Clean(rootRegistryPath)).Output()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to list the registry at %q: %w", ref, err)
+	}
+	var readmes []string
+	for _, filePath := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if path.Base(filePath) == "README.md" {
+			readmes = append(readmes, filePath)
+		}
+	}
+	return readmes, nil
+}
+
+// validateNewResourceDuplicates warns about modules and templates that were added since the base ref and look like
+// an existing resource in another namespace. Duplicates are never an error, because a reviewer has to decide whether
+// the new resource is different enough to stand on its own. It is skipped when no base ref is given.
+func validateNewResourceDuplicates(baseRef string) error {
+	if baseRef == "" {
+		return nil
+	}
+	existing, err := registryReadmesAtRef(baseRef)
+	if err != nil {
+		return err
+	}
+	fingerprints, err := loadDuplicateFingerprints()
+	if err != nil {
+		return err
+	}
+
+	isNew := func(f duplicateFingerprint) bool {
+		return !slices.Contains(existing, path.Clean(f.filePath))
+	}
+	numNew := 0
+	for _, f := range fingerprints {
+		if isNew(f) {
+			numNew++
+		}
+	}
+	for _, m := range findDuplicates(fingerprints, defaultDuplicateThreshold, isNew) {
+		added, other := m.a, m.b
+		if !isNew(added) {
+			added, other = other, added
+		}
+		kind := strings.TrimSuffix(other.resourceType, "s")
+		if !isNew(other) {
+			kind = "existing " + kind
+		}
+		logger.Warn(context.Background(), addFilePathToError(added.filePath, xerrors.Errorf(
+			"looks like a duplicate of the %s %q (%.0f%% similar: %s); consider contributing to it instead",
+			kind, other.id, m.score*100, m.breakdown(),
+		)).Error())
+	}
+	logger.Info(context.Background(), "checked new modules and templates for duplicates", "base", baseRef, "num_new", numNew)
+	return nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"os"
+	"path"
+	"slices"
+	"testing"
+)
+
+func TestDuplicateTerms(t *testing.T) {
+	t.Parallel()
+
+	got := duplicateTerms("AWS Region", "aws-region for the Coder workspace")
+	if !slices.Equal(got, []string{"aws", "region"}) {
+		t.Errorf("expected terms without stopwords or repeats, got %q", got)
+	}
+}
+
+func TestPrefixSimilarity(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		a, b     []string
+		expected float64
+	}{
+		{a: []string{"cli", "codex"}, b: []string{"claude", "code"}, expected: 1.0 / 3},
+		{a: []string{"installer", "jetbrains", "plugins"}, b: []string{"jetbrains", "plugin"}, expected: 2.0 / 3},
+		{a: []string{"ai", "aider"}, b: []string{"aider"}, expected: 1.0 / 2},
+		{a: []string{"code", "codex"}, b: []string{"code"}, expected: 1.0 / 2},
+	} {
+		if got := prefixSimilarity(tc.a, tc.b); got != tc.expected {
+			t.Errorf("expected similarity of %q and %q to be %v, got %v", tc.a, tc.b, tc.expected, got)
+		}
+	}
+}
+
+func TestTerraformFingerprintFeatures(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(path.Join(dir, "run.sh"), []byte("#!/usr/bin/env bash\n# Install the server\nset -euo pipefail\n\n  curl -fsSL https://example.com/install.sh | sh\nfi\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tf := parseTestTerraform(t, `
+variable "slug" {
+  default = "gateway"
+}
+data "coder_workspace" "me" {}
+resource "coder_script" "install" {
+  script = templatefile("${path.module}/run.sh", {})
+}
+resource "coder_script" "start" {
+  script = <<-EOT
+    server --port ${var.port} &
+  EOT
+}
+resource "coder_app" "ide" {
+  slug = "jetbrains-${each.key}"
+  url  = join("", ["jetbrains-gateway://connect#owner=", data.coder_workspace.me.name])
+}
+resource "coder_app" "web" {
+  slug = var.slug
+  url  = "https://localhost:8080"
+}
+module "vscode" {
+  source = "registry.coder.com/coder/vscode-web/coder"
+}`)
+	tf.dirPath = dir
+
+	resources := terraformResourceSet(tf)
+	if expected := []string{"coder_app", "coder_script", "data.coder_workspace"}; !slices.Equal(resources, expected) {
+		t.Errorf("expected resources %q, got %q", expected, resources)
+	}
+	apps := terraformAppTerms(tf)
+	if expected := []string{"gateway", "ide", "jetbrains", "web"}; !slices.Equal(apps, expected) {
+		t.Errorf("expected app terms %q, got %q", expected, apps)
+	}
+	r := catalogResource{
+		resourceType: "modules",
+		namespace:    "acme",
+		name:         "ide",
+		readme:       coderResourceReadme{body: "module \"ide\" {\n  source = \"registry.coder.com/acme/ide/coder\"\n}\nmodule \"jetbrains\" {\n  source = \"registry.coder.com/coder/jetbrains/coder\"\n}\n"},
+		terraform:    tf,
+	}
+	if modules, expected := registryModuleDependencies(r), []string{"coder/jetbrains", "coder/vscode-web"}; !slices.Equal(modules, expected) {
+		t.Errorf("expected module dependencies %q, got %q", expected, modules)
+	}
+	lines := terraformScriptLines(tf)
+	if expected := []string{"curl -fsSL https://example.com/install.sh | sh", "server --port ${var.port} &", "set -euo pipefail"}; !slices.Equal(lines, expected) {
+		t.Errorf("expected script lines %q, got %q", expected, lines)
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	t.Parallel()
+
+	fingerprint := func(resourceType, id string, name, description, tags, resources []string) duplicateFingerprint {
+		return duplicateFingerprint{
+			resourceType: resourceType,
+			id:           id,
+			filePath:     "registry/" + path.Dir(id) + "/" + resourceType + "/" + path.Base(id) + "/README.md",
+			features: map[duplicateFeature][]string{
+				duplicateFeatureName:        name,
+				duplicateFeatureDescription: description,
+				duplicateFeatureTags:        tags,
+				duplicateFeatureResources:   resources,
+			},
+		}
+	}
+	awsRegion := fingerprint("modules", "coder/aws-region", []string{"aws", "region"}, []string{"pick", "region"}, []string{"aws", "helper", "parameter"}, []string{"data.coder_parameter"})
+	doRegion := fingerprint("modules", "umair/do-region", []string{"digitalocean", "region"}, []string{"pick", "region"}, []string{"digitalocean", "helper", "parameter"}, []string{"data.coder_parameter"})
+	codeServer := fingerprint("modules", "coder/code-server", []string{"code", "server"}, []string{"vs", "code", "browser"}, []string{"ide", "web"}, []string{"coder_app", "coder_script"})
+	regionTemplate := fingerprint("templates", "acme/aws-region", []string{"aws", "region"}, []string{"pick", "region"}, []string{"aws", "helper", "parameter"}, []string{"data.coder_parameter"})
+	fingerprints := []duplicateFingerprint{awsRegion, doRegion, codeServer, regionTemplate}
+
+	matches := findDuplicates(fingerprints, defaultDuplicateThreshold, nil)
+	if len(matches) != 1 || matches[0].a.id != "coder/aws-region" || matches[0].b.id != "umair/do-region" {
+		t.Fatalf("expected only the region modules to match, got %d matches: %+v", len(matches), matches)
+	}
+	// Scripts are missing on both sides, so they don't count against the score.
+	if _, ok := matches[0].similarities[duplicateFeatureScripts]; ok {
+		t.Error("expected scripts to be left out of the comparison")
+	}
+	if got := matches[0].breakdown(); got != "name 33%, description 100%, tags 50%, resources 100%" {
+		t.Errorf("unexpected breakdown: %s", got)
+	}
+	if score := compareFingerprints(awsRegion, awsRegion).score; score != 1 {
+		t.Errorf("expected a resource to be identical to itself, got %v", score)
+	}
+
+	onlyCodeServer := func(f duplicateFingerprint) bool { return f.id == "coder/code-server" }
+	if matches := findDuplicates(fingerprints, defaultDuplicateThreshold, onlyCodeServer); len(matches) != 0 {
+		t.Errorf("expected no duplicates of code-server, got %+v", matches)
+	}
+}
+
+// TestFindDuplicatesInRegistry checks pairs of modules in this repo that reviewers consider duplicates, so that
+// tuning the features or weights doesn't stop them from being reported.
+func TestFindDuplicatesInRegistry(t *testing.T) {
+	t.Chdir("../..")
+
+	fingerprints, err := loadDuplicateFingerprints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := map[string]duplicateFingerprint{}
+	for _, f := range fingerprints {
+		if f.resourceType == "modules" {
+			byID[f.id] = f
+		}
+	}
+	for _, pair := range [][2]string{
+		{"harsh9485/jetbrains-plugins", "coder/jetbrains"},
+		{"coder/jetbrains-gateway", "coder/jetbrains"},
+		{"coder-labs/codex", "coder/claude-code"},
+	} {
+		a, okA := byID[pair[0]]
+		b, okB := byID[pair[1]]
+		if !okA || !okB {
+			t.Errorf("expected modules %q and %q to exist", pair[0], pair[1])
+			continue
+		}
+		if match := compareFingerprints(a, b); match.score < defaultDuplicateThreshold {
+			t.Errorf("expected %q and %q to score at least %v, got %.2f (%s)", pair[0], pair[1], defaultDuplicateThreshold, match.score, match.breakdown())
+		}
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 
+	"github.com/hashicorp/hcl/v2"
 	"golang.org/x/xerrors"
 )
 
@@ -28,3 +29,9 @@ func (vpe validationPhaseError) Error() string {
 func addFilePathToError(filePath string, err error) error {
 	return xerrors.Errorf("%q: %v", filePath, err)
 }
+
+// addRangeToError prefixes an error with the file and line of a Terraform source range, so that diagnostics for
+// Terraform files point at the exact block or attribute that caused them.
+func addRangeToError(rng hcl.Range, err error) error {
+	return addFilePathToError(fmt.Sprintf("%s:%d", rng.Filename, rng.Start.Line), err)
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+)
+
+// yamlErrorLineRe matches the line number in the syntax errors returned by yaml.v3, which are relative to the start
+// of the YAML document rather than the file it was taken from.
+var yamlErrorLineRe = regexp.MustCompile(`^yaml: line (\d+): `)
+
+// frontmatterFences returns the indexes of the lines that open and close the frontmatter of a Markdown file. Blank
+// lines before the opening fence are ignored.
+func frontmatterFences(lines []string) (openIdx int, closeIdx int, err error) {
+	const fence = "---"
+	openIdx = slices.IndexFunc(lines, func(line string) bool { return strings.TrimSpace(line) != "" })
+	if openIdx == -1 {
+		return 0, 0, xerrors.New("README is empty")
+	}
+	if strings.TrimSpace(lines[openIdx]) != fence {
+		return 0, 0, xerrors.New("README does not have two sets of frontmatter fences")
+	}
+	closeIdx = slices.IndexFunc(lines[openIdx+1:], func(line string) bool { return strings.TrimSpace(line) == fence })
+	if closeIdx == -1 {
+		return 0, 0, xerrors.New("README does not have two sets of frontmatter fences")
+	}
+	return openIdx, closeIdx + openIdx + 1, nil
+}
+
+// splitFrontmatter separates the YAML frontmatter of a Markdown file from its body. The frontmatter is returned
+// exactly as written, indentation included, along with the line of the file it starts on. It does not validate
+// whether the frontmatter is valid YAML.
+func splitFrontmatter(text string) (frontmatter string, startLine int, body string, err error) {
+	lines := strings.Split(text, "\n")
+	openIdx, closeIdx, err := frontmatterFences(lines)
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	frontmatter = strings.Join(lines[openIdx+1:closeIdx], "\n")
+	if strings.TrimSpace(frontmatter) == "" {
+		return "", 0, "", xerrors.New("readme has frontmatter fences but no frontmatter content")
+	}
+	return frontmatter + "\n", openIdx + 2, strings.TrimSpace(strings.Join(lines[closeIdx+1:], "\n")), nil
+}
+
+// decodeFrontmatter decodes the frontmatter of a Markdown file into out, which must point to a struct, and returns
+// the body of the file. Unlike a plain yaml.Unmarshal, it rejects duplicate keys, keys that the target type has no
+// field for at any depth, and values of the wrong shape (like a single string where a list is expected), reporting
+// each problem with its line in the file. allowedKeys lists the keys accepted at the top level, which may include
+// deprecated keys that are no longer decoded.
+func decodeFrontmatter(text string, out any, allowedKeys []string) (body string, errs []error) {
+	fm, startLine, body, err := splitFrontmatter(text)
+	if err != nil {
+		return "", []error{xerrors.Errorf("failed to parse frontmatter: %v", err)}
+	}
+
+	doc := yaml.Node{}
+	if err := yaml.Unmarshal([]byte(fm), &doc); err != nil {
+		msg := err.Error()
+		if match := yamlErrorLineRe.FindStringSubmatch(msg); match != nil {
+			line, _ := strconv.Atoi(match[1])
+			msg = fmt.Sprintf("line %d: %s", line+startLine-1, strings.TrimPrefix(msg, match[0]))
+		}
+		return "", []error{xerrors.Errorf("failed to parse frontmatter: %s", msg)}
+	}
+	if len(doc.Content) == 0 {
+		return "", []error{xerrors.New("failed to parse frontmatter: frontmatter only contains comments")}
+	}
+
+	checker := frontmatterChecker{lineOffset: startLine - 1}
+	errs = checker.check(doc.Content[0], reflect.TypeOf(out).Elem(), "", allowedKeys)
+	if len(errs) != 0 {
+		return "", errs
+	}
+	if err := doc.Decode(out); err != nil {
+		return "", []error{xerrors.Errorf("failed to parse frontmatter: %v", err)}
+	}
+	return body, nil
+}
+
+// frontmatterChecker walks a YAML node tree alongside the Go type it will be decoded into.
+type frontmatterChecker struct {
+	lineOffset int
+}
+
+func (c frontmatterChecker) errorf(n *yaml.Node, format string, args ...any) error {
+	return xerrors.Errorf("line %d: %s", n.Line+c.lineOffset, fmt.Sprintf(format, args...))
+}
+
+// yamlNodeDescription describes the shape of a YAML value for error messages.
+func yamlNodeDescription(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "a map"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	switch n.Tag {
+	case "!!bool":
+		return "a boolean"
+	case "!!int", "!!float":
+		return "a number"
+	default:
+		return "a string"
+	}
+}
+
+// check validates the node n against the type t it will be decoded into. keyPath is the dotted path of the node, used
+// in error messages, and allowedKeys overrides the keys allowed in n when it is non-nil.
+func (c frontmatterChecker) check(n *yaml.Node, t reflect.Type, keyPath string, allowedKeys []string) []error {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Tag == "!!null" {
+		return nil
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var expected string
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		expected = "a map"
+		if n.Kind == yaml.MappingNode {
+			return c.checkMapping(n, t, keyPath, allowedKeys)
+		}
+	case reflect.Slice:
+		expected = "a list"
+		if n.Kind == yaml.SequenceNode {
+			var errs []error
+			for i, item := range n.Content {
+				errs = append(errs, c.check(item, t.Elem(), fmt.Sprintf("%s[%d]", keyPath, i), nil)...)
+			}
+			return errs
+		}
+	case reflect.Bool:
+		expected = "a boolean"
+		if n.Kind == yaml.ScalarNode && n.Tag == "!!bool" {
+			return nil
+		}
+	default:
+		// yaml.v3 decodes any scalar into a string.
+		expected = "a string"
+		if n.Kind == yaml.ScalarNode {
+			return nil
+		}
+	}
+
+	if keyPath == "" {
+		return []error{c.errorf(n, "frontmatter must be %s, got %s", expected, yamlNodeDescription(n))}
+	}
+	return []error{c.errorf(n, "%q must be %s, got %s", keyPath, expected, yamlNodeDescription(n))}
+}
+
+func (c frontmatterChecker) checkMapping(n *yaml.Node, t reflect.Type, keyPath string, allowedKeys []string) []error {
+	fields := map[string]reflect.Type{}
+	if t.Kind() == reflect.Struct {
+		for _, field := range reflect.VisibleFields(t) {
+			if key := yamlFieldName(field); key != "" {
+				fields[key] = field.Type
+			}
+		}
+		if allowedKeys == nil {
+			allowedKeys = frontmatterKeys(t)
+		}
+	}
+
+	var errs []error
+	seen := map[string]int{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		keyNode, valueNode := n.Content[i], n.Content[i+1]
+		key := keyNode.Value
+		fullKey := key
+		if keyPath != "" {
+			fullKey = keyPath + "." + key
+		}
+
+		if firstLine, ok := seen[key]; ok {
+			errs = append(errs, c.errorf(keyNode, "duplicate key %q (first defined on line %d)", fullKey, firstLine+c.lineOffset))
+			continue
+		}
+		seen[key] = keyNode.Line
+
+		if t.Kind() == reflect.Map {
+			errs = append(errs, c.check(valueNode, t.Elem(), fullKey, nil)...)
+			continue
+		}
+		if !slices.Contains(allowedKeys, key) {
+			errs = append(errs, c.errorf(keyNode, "detected unknown key %q (allowed: %s)", fullKey, strings.Join(allowedKeys, ", ")))
+			continue
+		}
+		if fieldType, ok := fields[key]; ok {
+			errs = append(errs, c.check(valueNode, fieldType, fullKey, nil)...)
+		}
+	}
+	return errs
+}
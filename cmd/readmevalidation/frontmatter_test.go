@@ -0,0 +1,102 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDecodeFrontmatter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		text         string
+		expectedErrs []string
+		expectedBody string
+		check        func(t *testing.T, fm coderSkillsFrontmatter)
+	}{
+		{
+			name:         "nested frontmatter keeps its indentation",
+			text:         "\n---\nicon: ../../../.icons/coder.svg\nsources:\n  - repo: coder/skills@v1\n    skills:\n      setup:\n        tags: [\"a: b\"]\n---\n\n# Skills\n",
+			expectedBody: "# Skills",
+			check: func(t *testing.T, fm coderSkillsFrontmatter) {
+				if len(fm.Sources) != 1 || !slices.Equal(fm.Sources[0].Skills["setup"].Tags, []string{"a: b"}) {
+					t.Errorf("unexpected frontmatter: %+v", fm)
+				}
+			},
+		},
+		{
+			name:         "missing fences",
+			text:         "# Skills\n",
+			expectedErrs: []string{"failed to parse frontmatter: README does not have two sets of frontmatter fences"},
+		},
+		{
+			name:         "syntax errors point at the file line",
+			text:         "---\nicon: x\nsources: [\n---\n",
+			expectedErrs: []string{"failed to parse frontmatter: line 3: "},
+		},
+		{
+			name: "duplicate keys at any depth",
+			text: "---\nicon: a\nsources:\n  - repo: coder/skills\n    repo: coder/other\nicon: b\n---\n",
+			expectedErrs: []string{
+				`line 5: duplicate key "sources[0].repo" (first defined on line 4)`,
+				`line 6: duplicate key "icon" (first defined on line 2)`,
+			},
+		},
+		{
+			name: "unknown keys at any depth",
+			text: "---\nsource: coder/skills\nsources:\n  - repo: coder/skills\n    skills:\n      setup:\n        title: Setup\n---\n",
+			expectedErrs: []string{
+				`line 2: detected unknown key "source" (allowed: icon, sources)`,
+				`line 7: detected unknown key "sources[0].skills.setup.title" (allowed: display_name, description, icon, tags)`,
+			},
+		},
+		{
+			name: "wrong types",
+			text: "---\nicon: [a]\nsources:\n  - repo: coder/skills\n    skills:\n      setup:\n        tags: ide\n---\n",
+			expectedErrs: []string{
+				`line 2: "icon" must be a string, got a list`,
+				`line 7: "sources[0].skills.setup.tags" must be a list, got a string`,
+			},
+		},
+		{
+			name:         "frontmatter that is not a map",
+			text:         "---\n- icon\n---\n",
+			expectedErrs: []string{"line 2: frontmatter must be a map, got a list"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fm := coderSkillsFrontmatter{}
+			body, errs := decodeFrontmatter(tc.text, &fm, supportedSkillsTopLevelKeys)
+			if len(errs) != len(tc.expectedErrs) {
+				t.Fatalf("expected %d errors, got: %v", len(tc.expectedErrs), errs)
+			}
+			for i, expected := range tc.expectedErrs {
+				if !strings.Contains(errs[i].Error(), expected) {
+					t.Errorf("expected error containing %q, got: %v", expected, errs[i])
+				}
+			}
+			if body != tc.expectedBody {
+				t.Errorf("expected body %q, got %q", tc.expectedBody, body)
+			}
+			if tc.check != nil {
+				tc.check(t, fm)
+			}
+		})
+	}
+}
+
+func TestDecodeFrontmatterBooleans(t *testing.T) {
+	t.Parallel()
+
+	fm := coderResourceFrontmatter{}
+	_, errs := decodeFrontmatter("---\ndescription: d\nverified: \"yes\"\nmaintainer_github: coder\n---\n", &fm, supportedCoderResourceStructKeys)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `line 3: "verified" must be a boolean, got a string`) {
+		t.Fatalf("expected a single type error for verified, got: %v", errs)
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+)
+
+// frontmatterFormat describes how the frontmatter of one kind of README is formatted.
+type frontmatterFormat struct {
+	frontmatter reflect.Type
+	// keys lists the top-level keys in canonical order.
+	keys []string
+}
+
+// frontmatterFormatForPath returns the frontmatter format of a README, based on where it lives in the registry.
+func frontmatterFormatForPath(filePath string) (frontmatterFormat, error) {
+	parts := strings.Split(path.Clean(filePath), "/")
+	if parts[len(parts)-1] != "README.md" {
+		return frontmatterFormat{}, xerrors.Errorf("%q is not a README.md file", filePath)
+	}
+	switch {
+	case len(parts) >= 4 && slices.Contains(supportedResourceTypes, parts[len(parts)-3]):
+		return frontmatterFormat{frontmatter: reflect.TypeFor[coderResourceFrontmatter](), keys: supportedCoderResourceStructKeys}, nil
+	case len(parts) >= 3 && parts[len(parts)-2] == "skills":
+		return frontmatterFormat{frontmatter: reflect.TypeFor[coderSkillsFrontmatter](), keys: supportedSkillsTopLevelKeys}, nil
+	default:
+		return frontmatterFormat{frontmatter: reflect.TypeFor[contributorProfileFrontmatter](), keys: supportedContributorProfileStructKeys}, nil
+	}
+}
+
+// normalizeTag converts a tag into its canonical form: trimmed, lowercase, and with spaces and underscores replaced by
+// hyphens.
+func normalizeTag(tag string) string {
+	fields := strings.FieldsFunc(strings.ToLower(tag), func(r rune) bool {
+		return r == ' ' || r == '_' || r == '\t'
+	})
+	return strings.Join(fields, "-")
+}
+
+// frontmatterFormatter rewrites README frontmatter into canonical order and style.
+type frontmatterFormatter struct {
+	// tagSynonyms maps tag synonyms from the tag vocabulary to their canonical tag.
+	tagSynonyms map[string]string
+}
+
+// format returns the text of a README with its frontmatter rewritten into canonical order and style. The frontmatter
+// must decode cleanly, and everything outside of it is left untouched.
+func (f frontmatterFormatter) format(text string, format frontmatterFormat) (string, []error) {
+	if _, errs := decodeFrontmatter(text, reflect.New(format.frontmatter).Interface(), format.keys); len(errs) != 0 {
+		return "", errs
+	}
+
+	lines := strings.Split(text, "\n")
+	openIdx, closeIdx, err := frontmatterFences(lines)
+	if err != nil {
+		return "", []error{err}
+	}
+	doc := yaml.Node{}
+	if err := yaml.Unmarshal([]byte(strings.Join(lines[openIdx+1:closeIdx], "\n")), &doc); err != nil {
+		return "", []error{err}
+	}
+	f.formatNode(doc.Content[0], format.frontmatter, "", format.keys)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc.Content[0]); err != nil {
+		return "", []error{err}
+	}
+	if err := encoder.Close(); err != nil {
+		return "", []error{err}
+	}
+
+	formatted := slices.Concat(lines[:openIdx+1], []string{strings.TrimSuffix(buf.String(), "\n")}, lines[closeIdx:])
+	return strings.Join(formatted, "\n"), nil
+}
+
+// formatNode rewrites a node in place into canonical style, alongside the Go type it decodes into: mapping keys are
+// sorted into field declaration order, lists of scalars use flow style, quotes are dropped wherever YAML doesn't need
+// them, and tags are normalized, mapped from synonyms to canonical tags, and de-duplicated. keyOrder overrides the key
+// order of n when non-nil.
+func (f frontmatterFormatter) formatNode(n *yaml.Node, t reflect.Type, key string, keyOrder []string) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if n.Style == yaml.SingleQuotedStyle || n.Style == yaml.DoubleQuotedStyle {
+			// The encoder adds quotes back if the plain value would be read as anything other than a string.
+			n.Style = 0
+		}
+	case yaml.SequenceNode:
+		if t.Kind() != reflect.Slice {
+			return
+		}
+		if key == "tags" {
+			var items []*yaml.Node
+			var seen []string
+			for _, item := range n.Content {
+				tag := normalizeTag(item.Value)
+				if canonical, ok := f.tagSynonyms[tag]; ok {
+					tag = canonical
+				}
+				if item.Kind != yaml.ScalarNode || tag == "" || slices.Contains(seen, tag) {
+					continue
+				}
+				seen = append(seen, tag)
+				item.Value = tag
+				items = append(items, item)
+			}
+			n.Content = items
+		}
+		n.Style = 0
+		if !slices.ContainsFunc(n.Content, func(item *yaml.Node) bool { return item.Kind != yaml.ScalarNode }) {
+			n.Style = yaml.FlowStyle
+		}
+		for _, item := range n.Content {
+			f.formatNode(item, t.Elem(), "", nil)
+		}
+	case yaml.MappingNode:
+		n.Style = 0
+		switch t.Kind() {
+		case reflect.Map:
+			for i := 1; i < len(n.Content); i += 2 {
+				f.formatNode(n.Content[i], t.Elem(), n.Content[i-1].Value, nil)
+			}
+		case reflect.Struct:
+			if keyOrder == nil {
+				keyOrder = frontmatterKeys(t)
+			}
+			fields := map[string]reflect.Type{}
+			for _, field := range reflect.VisibleFields(t) {
+				if name := yamlFieldName(field); name != "" {
+					fields[name] = field.Type
+				}
+			}
+
+			var pairs [][2]*yaml.Node
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				pairs = append(pairs, [2]*yaml.Node{n.Content[i], n.Content[i+1]})
+			}
+			rank := func(key string) int {
+				if idx := slices.Index(keyOrder, key); idx != -1 {
+					return idx
+				}
+				return len(keyOrder)
+			}
+			slices.SortStableFunc(pairs, func(a, b [2]*yaml.Node) int {
+				return rank(a[0].Value) - rank(b[0].Value)
+			})
+			n.Content = n.Content[:0]
+			for _, pair := range pairs {
+				f.formatNode(pair[0], reflect.TypeFor[string](), "", nil)
+				fieldType, ok := fields[pair[0].Value]
+				if !ok {
+					// Deprecated keys have no field, and are all strings.
+					fieldType = reflect.TypeFor[string]()
+				}
+				f.formatNode(pair[1], fieldType, pair[0].Value, nil)
+				n.Content = append(n.Content, pair[0], pair[1])
+			}
+		}
+	}
+}
+
+// registryReadmeFiles returns the paths of every contributor, resource, and skills README in the registry.
+func registryReadmeFiles() ([]string, error) {
+	var all []readme
+	contributors, err := aggregateContributorReadmeFiles()
+	if err != nil {
+		return nil, err
+	}
+	all = append(all, contributors...)
+	for _, resourceType := range supportedResourceTypes {
+		resources, err := aggregateCoderResourceReadmeFiles(resourceType)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, resources...)
+	}
+	skills, err := aggregateSkillsReadmeFiles()
+	if err != nil {
+		return nil, err
+	}
+	all = append(all, skills...)
+
+	var paths []string
+	for _, rm := range all {
+		paths = append(paths, rm.filePath)
+	}
+	return paths, nil
+}
+
+// runFmtCommand formats the frontmatter of the READMEs given as arguments, or of every README in the registry. With
+// --check, it lists the files that are not formatted instead of rewriting them.
+func runFmtCommand(args []string) error {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	check := flags.Bool("check", false, "List READMEs whose frontmatter is not formatted, without changing them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	vocab, err := loadTagVocabulary()
+	if err != nil {
+		return err
+	}
+	formatter := frontmatterFormatter{tagSynonyms: vocab.synonyms()}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths, err = registryReadmeFiles()
+		if err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	var unformatted []string
+	for _, filePath := range paths {
+		format, err := frontmatterFormatForPath(filePath)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			errs = append(errs, addFilePathToError(filePath, err))
+			continue
+		}
+		formatted, fmtErrs := formatter.format(string(content), format)
+		for _, err := range fmtErrs {
+			errs = append(errs, addFilePathToError(filePath, err))
+		}
+		if len(fmtErrs) != 0 || formatted == string(content) {
+			continue
+		}
+
+		unformatted = append(unformatted, filePath)
+		if *check {
+			fmt.Println(filePath)
+			continue
+		}
+		if err := os.WriteFile(filePath, []byte(formatted), 0o644); err != nil {
+			errs = append(errs, addFilePathToError(filePath, err))
+			continue
+		}
+		logger.Info(context.Background(), "formatted frontmatter", "path", filePath)
+	}
+
+	if len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseReadme,
+			errors: errs,
+		}
+	}
+	if *check && len(unformatted) != 0 {
+		return xerrors.Errorf("%d README(s) have unformatted frontmatter; format them with \"./readmevalidation fmt\"", len(unformatted))
+	}
+	return nil
+}
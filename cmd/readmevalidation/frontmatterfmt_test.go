@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatFrontmatter(t *testing.T) {
+	t.Parallel()
+
+	formatter := frontmatterFormatter{tagSynonyms: map[string]string{"editor": "ide"}}
+	testCases := []struct {
+		name         string
+		filePath     string
+		text         string
+		expected     string
+		expectedErrs []string
+	}{
+		{
+			name:     "resource keys, quotes, and tag synonyms",
+			filePath: "registry/coder/modules/code-server/README.md",
+			text: "---\ntags: [\"IDE\", web, editor, \"Web IDE\"]\nicon: \"../../../../.icons/code.svg\" # keep me\nmaintainer_github: \"coder\"\n" +
+				"description: 'VS Code: in the browser'\ndisplay_name: \"1.0\"\nverified: true\n---\n\n# code-server\n\n  Body is   untouched.\n",
+			expected: "---\ndisplay_name: \"1.0\"\ndescription: 'VS Code: in the browser'\nicon: ../../../../.icons/code.svg # keep me\nverified: true\n" +
+				"tags: [ide, web, web-ide]\nmaintainer_github: coder\n---\n\n# code-server\n\n  Body is   untouched.\n",
+		},
+		{
+			name:     "contributor",
+			filePath: "registry/jane/README.md",
+			text:     "---\nstatus: community\ngithub: jane\ndisplay_name: Jane\n---\n# Jane\n",
+			expected: "---\ndisplay_name: Jane\ngithub: jane\nstatus: community\n---\n# Jane\n",
+		},
+		{
+			name:     "nested skills frontmatter",
+			filePath: "registry/coder/skills/README.md",
+			text: "---\nsources:\n  - skills:\n      setup:\n        tags:\n          - Coder\n          - coder\n        display_name: Setup\n    repo: coder/skills@v1\n" +
+				"icon: ../../../.icons/coder.svg\n---\n\n# Skills\n",
+			expected: "---\nicon: ../../../.icons/coder.svg\nsources:\n  - repo: coder/skills@v1\n    skills:\n      setup:\n        display_name: Setup\n        tags: [coder]\n---\n\n# Skills\n",
+		},
+		{
+			name:         "invalid frontmatter is not formatted",
+			filePath:     "registry/jane/README.md",
+			text:         "---\ndisplay_name: Jane\nname: jane\n---\n",
+			expectedErrs: []string{`line 3: detected unknown key "name"`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			format, err := frontmatterFormatForPath(tc.filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			formatted, errs := formatter.format(tc.text, format)
+			if len(errs) != len(tc.expectedErrs) {
+				t.Fatalf("expected %d errors, got: %v", len(tc.expectedErrs), errs)
+			}
+			for i, expected := range tc.expectedErrs {
+				if !strings.Contains(errs[i].Error(), expected) {
+					t.Errorf("expected error containing %q, got: %v", expected, errs[i])
+				}
+			}
+			if formatted != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, formatted)
+			}
+
+			// Formatting must be idempotent.
+			if len(errs) == 0 {
+				if again, _ := formatter.format(formatted, format); again != formatted {
+					t.Errorf("formatting again changed the README:\n%s", again)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// githubIdentityMode controls how far GitHub usernames in contributor profiles are verified.
+type githubIdentityMode string
+
+const (
+	// githubIdentityModeOff only checks usernames against GitHub's login grammar.
+	githubIdentityModeOff githubIdentityMode = "off"
+	// githubIdentityModeCacheOnly verifies usernames against the on-disk cache, without making any network requests.
+	githubIdentityModeCacheOnly githubIdentityMode = "cache-only"
+	// githubIdentityModeOnline verifies usernames against the GitHub API, refreshing the on-disk cache as it goes.
+	githubIdentityModeOnline githubIdentityMode = "online"
+
+	defaultGithubAPIURL        = "https://api.github.com"
+	defaultGithubIdentityCache = "./.github/github-identity-cache.json"
+
+	// githubIdentityCacheTTL is how long a cached lookup is trusted in online mode before it is refreshed.
+	githubIdentityCacheTTL = 30 * 24 * time.Hour
+
+	// maxGithubLoginLength is the longest login GitHub allows.
+	maxGithubLoginLength = 39
+)
+
+var githubIdentityModes = []githubIdentityMode{githubIdentityModeOff, githubIdentityModeCacheOnly, githubIdentityModeOnline}
+
+// errGithubAccountNotFound is returned by a githubIdentityLookup when no account has the requested login.
+var errGithubAccountNotFound = xerrors.New("GitHub account does not exist")
+
+// errGithubAccountNotCached is returned by the cache when running in cache-only mode and a login was never looked up.
+var errGithubAccountNotCached = xerrors.New("GitHub account is not in the identity cache")
+
+// githubAccount is the subset of a GitHub user or organization that the registry cares about.
+type githubAccount struct {
+	// Login is the canonical casing of the account's login.
+	Login string `json:"login"`
+	// Type is either "User" or "Organization".
+	Type string `json:"type"`
+}
+
+// githubIdentityLookup resolves a GitHub login to the account it belongs to. Logins are case-insensitive, so
+// implementations must accept any casing and return the canonical one.
+type githubIdentityLookup interface {
+	lookupAccount(ctx context.Context, login string) (githubAccount, error)
+}
+
+// isValidGithubLogin reports whether a login follows GitHub's login grammar: alphanumeric characters and single
+// hyphens, not starting or ending with a hyphen, and at most 39 characters long.
+func isValidGithubLogin(login string) bool {
+	if login == "" || len(login) > maxGithubLoginLength {
+		return false
+	}
+	if strings.HasPrefix(login, "-") || strings.HasSuffix(login, "-") || strings.Contains(login, "--") {
+		return false
+	}
+	for _, r := range login {
+		isAlphanumeric := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		if !isAlphanumeric && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// httpGithubIdentityLookup looks up accounts through the GitHub REST API.
+type httpGithubIdentityLookup struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+var _ githubIdentityLookup = httpGithubIdentityLookup{}
+
+func (h httpGithubIdentityLookup) lookupAccount(ctx context.Context, login string) (githubAccount, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(h.baseURL, "/")+"/users/"+url.PathEscape(login), nil)
+	if err != nil {
+		return githubAccount{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if h.token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.token)
+	}
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		return githubAccount{}, xerrors.Errorf("failed to look up GitHub account %q: %v", login, err)
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		account := githubAccount{}
+		if err := json.NewDecoder(res.Body).Decode(&account); err != nil {
+			return githubAccount{}, xerrors.Errorf("failed to decode GitHub account %q: %v", login, err)
+		}
+		return account, nil
+	case http.StatusNotFound:
+		return githubAccount{}, errGithubAccountNotFound
+	default:
+		return githubAccount{}, xerrors.Errorf("unexpected status %q when looking up GitHub account %q", res.Status, login)
+	}
+}
+
+// githubIdentityCacheEntry is a single cached lookup. Lookups for accounts that do not exist are cached too, so that
+// typos are still reported when running offline.
+type githubIdentityCacheEntry struct {
+	Account   *githubAccount `json:"account"`
+	CheckedAt time.Time      `json:"checked_at"`
+}
+
+// cachedGithubIdentityLookup wraps another lookup with an on-disk JSON cache keyed by lowercase login. When the
+// wrapped lookup is nil, the cache is used on its own and never makes network requests.
+type cachedGithubIdentityLookup struct {
+	filePath string
+	upstream githubIdentityLookup
+	entries  map[string]githubIdentityCacheEntry
+	now      func() time.Time
+	dirty    bool
+}
+
+var _ githubIdentityLookup = &cachedGithubIdentityLookup{}
+
+func loadGithubIdentityCache(filePath string, upstream githubIdentityLookup) (*cachedGithubIdentityLookup, error) {
+	cache := &cachedGithubIdentityLookup{
+		filePath: filePath,
+		upstream: upstream,
+		entries:  map[string]githubIdentityCacheEntry{},
+		now:      time.Now,
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cache, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &cache.entries); err != nil {
+		return nil, addFilePathToError(filePath, err)
+	}
+	return cache, nil
+}
+
+func (c *cachedGithubIdentityLookup) lookupAccount(ctx context.Context, login string) (githubAccount, error) {
+	key := strings.ToLower(login)
+	entry, cached := c.entries[key]
+	isFresh := cached && c.now().Sub(entry.CheckedAt) < githubIdentityCacheTTL
+
+	if c.upstream == nil || isFresh {
+		if !cached {
+			return githubAccount{}, errGithubAccountNotCached
+		}
+		if entry.Account == nil {
+			return githubAccount{}, errGithubAccountNotFound
+		}
+		return *entry.Account, nil
+	}
+
+	account, err := c.upstream.lookupAccount(ctx, login)
+	switch {
+	case err == nil:
+		c.entries[key] = githubIdentityCacheEntry{Account: &account, CheckedAt: c.now().UTC()}
+	case errors.Is(err, errGithubAccountNotFound):
+		c.entries[key] = githubIdentityCacheEntry{CheckedAt: c.now().UTC()}
+	default:
+		return githubAccount{}, err
+	}
+	c.dirty = true
+	return account, err
+}
+
+// save writes the cache back to disk if any lookups were added or refreshed.
+func (c *cachedGithubIdentityLookup) save() error {
+	if !c.dirty {
+		return nil
+	}
+	content, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.filePath, append(content, '\n'), 0o644)
+}
+
+// validateContributorGithubIdentity confirms that a contributor's GitHub username belongs to a real account, and that
+// the username is written with the account's canonical casing so that mentions and CODEOWNERS entries resolve.
+// Organizations can't be CODEOWNERS, so they are only accepted when the namespace's owners are overridden.
+func validateContributorGithubIdentity(ctx context.Context, lookup githubIdentityLookup, con contributorProfileReadme, hasCodeownersOverride bool) error {
+	login := *con.frontmatter.GithubUsername
+	account, err := lookup.lookupAccount(ctx, login)
+	if err != nil {
+		if errors.Is(err, errGithubAccountNotFound) {
+			return xerrors.Errorf("GitHub username %q does not belong to any account", login)
+		}
+		return err
+	}
+
+	if account.Login != login {
+		return xerrors.Errorf("GitHub username %q must use the account's canonical casing %q", login, account.Login)
+	}
+	if account.Type != "User" && !hasCodeownersOverride {
+		return xerrors.Errorf("GitHub username %q belongs to an account of type %q rather than a user, so it cannot own the namespace in CODEOWNERS; assign a team to namespace %q in %q", login, account.Type, con.namespace, codeownersOverridesPath)
+	}
+	// Namespaces don't have to be named after their owner, but when they are, any difference beyond casing is
+	// almost certainly a typo in one of the two.
+	if !strings.EqualFold(con.namespace, account.Login) && strings.EqualFold(strings.ReplaceAll(con.namespace, "-", ""), strings.ReplaceAll(account.Login, "-", "")) {
+		return xerrors.Errorf("namespace %q does not match GitHub account %q", con.namespace, account.Login)
+	}
+	return nil
+}
+
+// validateGithubIdentities runs the identity-check phase for every contributor with a GitHub username.
+func validateGithubIdentities(ctx context.Context, lookup githubIdentityLookup, contributors map[string]contributorProfileReadme, overrides codeownersOverrides) error {
+	var namespaces []string
+	for namespace, con := range contributors {
+		if con.frontmatter.GithubUsername != nil {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	slices.Sort(namespaces)
+
+	var errs []error
+	for _, namespace := range namespaces {
+		con := contributors[namespace]
+		_, hasCodeownersOverride := overrides.Namespaces[namespace]
+		err := validateContributorGithubIdentity(ctx, lookup, con, hasCodeownersOverride)
+		if errors.Is(err, errGithubAccountNotCached) {
+			logger.Warn(ctx, "skipping GitHub identity check for account missing from cache", "namespace", namespace, "github", *con.frontmatter.GithubUsername)
+			continue
+		}
+		if err != nil {
+			errs = append(errs, addFilePathToError(con.filePath, err))
+		}
+	}
+
+	if len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseIdentity,
+			errors: errs,
+		}
+	}
+	return nil
+}
+
+// newGithubIdentityLookup builds the lookup for the requested mode. It returns nil when identity checks are off.
+func newGithubIdentityLookup(mode githubIdentityMode, cachePath string) (*cachedGithubIdentityLookup, error) {
+	switch mode {
+	case githubIdentityModeOff:
+		return nil, nil
+	case githubIdentityModeCacheOnly:
+		return loadGithubIdentityCache(cachePath, nil)
+	case githubIdentityModeOnline:
+		return loadGithubIdentityCache(cachePath, httpGithubIdentityLookup{
+			client:  &http.Client{Timeout: 10 * time.Second},
+			baseURL: defaultGithubAPIURL,
+			token:   os.Getenv("GITHUB_TOKEN"),
+		})
+	default:
+		return nil, xerrors.Errorf("unknown GitHub identity mode %q", mode)
+	}
+}
+
+// validateAllGithubIdentities verifies the GitHub username of every contributor profile using the given mode. In
+// online mode, the cache is written back so that later cache-only runs (such as offline CI) can reuse the lookups.
+func validateAllGithubIdentities(mode githubIdentityMode, cachePath string) error {
+	lookup, err := newGithubIdentityLookup(mode, cachePath)
+	if err != nil || lookup == nil {
+		return err
+	}
+
+	allReadmeFiles, err := aggregateContributorReadmeFiles()
+	if err != nil {
+		return err
+	}
+	contributors, err := parseContributorFiles(allReadmeFiles)
+	if err != nil {
+		return err
+	}
+
+	overrides, err := loadCodeownersOverrides()
+	if err != nil {
+		return err
+	}
+
+	validationErr := validateGithubIdentities(context.Background(), lookup, contributors, overrides)
+	if err := lookup.save(); err != nil {
+		return addFilePathToError(cachePath, err)
+	}
+	if validationErr != nil {
+		return validationErr
+	}
+	logger.Info(context.Background(), "verified GitHub identities of all contributors", "mode", string(mode))
+	return nil
+}
+
+func joinGithubIdentityModes() string {
+	names := make([]string, 0, len(githubIdentityModes))
+	for _, m := range githubIdentityModes {
+		names = append(names, string(m))
+	}
+	return strings.Join(names, ", ")
+}
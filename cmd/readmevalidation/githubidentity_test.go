@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsValidGithubLogin(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]bool{
+		"octocat":                    true,
+		"Octo-Cat":                   true,
+		"a":                          true,
+		"123":                        true,
+		"":                           false,
+		"-octocat":                   false,
+		"octocat-":                   false,
+		"octo--cat":                  false,
+		"octo_cat":                   false,
+		"octo cat":                   false,
+		"@octocat":                   false,
+		strings.Repeat("a", 39):      true,
+		strings.Repeat("a", 40):      false,
+		"https://github.com/octocat": false,
+	}
+	for login, expected := range cases {
+		if got := isValidGithubLogin(login); got != expected {
+			t.Errorf("isValidGithubLogin(%q) = %v, expected %v", login, got, expected)
+		}
+	}
+}
+
+func newTestGithubServer(t *testing.T, accounts map[string]githubAccount) (*httptest.Server, *int) {
+	t.Helper()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		login, ok := strings.CutPrefix(r.URL.Path, "/users/")
+		account, exists := accounts[strings.ToLower(login)]
+		if !ok || !exists {
+			http.NotFound(w, r)
+			return
+		}
+		if account.Type == "" {
+			account.Type = "User"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"login":"` + account.Login + `","type":"` + account.Type + `","id":1}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestHTTPGithubIdentityLookup(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newTestGithubServer(t, map[string]githubAccount{"octocat": {Login: "OctoCat"}})
+	lookup := httpGithubIdentityLookup{client: server.Client(), baseURL: server.URL}
+
+	account, err := lookup.lookupAccount(context.Background(), "octocat")
+	if err != nil {
+		t.Fatalf("Unexpected lookup error: %v", err)
+	}
+	if account.Login != "OctoCat" || account.Type != "User" {
+		t.Errorf("Unexpected account: %+v", account)
+	}
+
+	if _, err := lookup.lookupAccount(context.Background(), "ghost"); !errors.Is(err, errGithubAccountNotFound) {
+		t.Errorf("Expected errGithubAccountNotFound, got: %v", err)
+	}
+}
+
+func TestCachedGithubIdentityLookup(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newTestGithubServer(t, map[string]githubAccount{"octocat": {Login: "OctoCat"}})
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+
+	online, err := loadGithubIdentityCache(cachePath, httpGithubIdentityLookup{client: server.Client(), baseURL: server.URL})
+	if err != nil {
+		t.Fatalf("Unexpected error loading cache: %v", err)
+	}
+	for _, login := range []string{"octocat", "OCTOCAT", "ghost"} {
+		_, _ = online.lookupAccount(context.Background(), login)
+	}
+	if *requests != 2 {
+		t.Errorf("Expected fresh cache entries to be reused, got %d requests", *requests)
+	}
+	if err := online.save(); err != nil {
+		t.Fatalf("Unexpected error saving cache: %v", err)
+	}
+
+	offline, err := loadGithubIdentityCache(cachePath, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error loading cache: %v", err)
+	}
+	// Cache-only lookups must never expire, since there is no way to refresh them.
+	offline.now = func() time.Time { return time.Now().Add(2 * githubIdentityCacheTTL) }
+
+	if account, err := offline.lookupAccount(context.Background(), "octocat"); err != nil || account.Login != "OctoCat" {
+		t.Errorf("Expected cached account OctoCat, got %+v (error: %v)", account, err)
+	}
+	if _, err := offline.lookupAccount(context.Background(), "ghost"); !errors.Is(err, errGithubAccountNotFound) {
+		t.Errorf("Expected cached missing account to be reported as not found, got: %v", err)
+	}
+	if _, err := offline.lookupAccount(context.Background(), "newcomer"); !errors.Is(err, errGithubAccountNotCached) {
+		t.Errorf("Expected errGithubAccountNotCached, got: %v", err)
+	}
+	if *requests != 2 {
+		t.Errorf("Expected cache-only lookups to make no requests, got %d requests", *requests)
+	}
+}
+
+func TestValidateGithubIdentities(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newTestGithubServer(t, map[string]githubAccount{
+		"octocat":  {Login: "OctoCat"},
+		"coderlab": {Login: "coderlab"},
+		"alice":    {Login: "alice"},
+		"acme":     {Login: "acme", Type: "Organization"},
+		"coder":    {Login: "coder", Type: "Organization"},
+	})
+	lookup := httpGithubIdentityLookup{client: server.Client(), baseURL: server.URL}
+
+	str := func(s string) *string { return &s }
+	contributors := map[string]contributorProfileReadme{
+		"valid":     newTestContributor("valid", str("OctoCat")),
+		"casing":    newTestContributor("casing", str("octocat")),
+		"missing":   newTestContributor("missing", str("ghost")),
+		"coder-lab": newTestContributor("coder-lab", str("coderlab")),
+		"no-github": newTestContributor("no-github", nil),
+		"alice":     newTestContributor("alice", str("alice")),
+		"acme":      newTestContributor("acme", str("acme")),
+		"coder":     newTestContributor("coder", str("coder")),
+	}
+	overrides := codeownersOverrides{Namespaces: map[string][]string{"coder": {"@coder/maintainers"}}}
+
+	err := validateGithubIdentities(context.Background(), lookup, contributors, overrides)
+	var phaseErr validationPhaseError
+	if !errors.As(err, &phaseErr) {
+		t.Fatalf("Expected validation phase error, got: %v", err)
+	}
+
+	expectedErrs := []string{
+		`"registry/casing/README.md": GitHub username "octocat" must use the account's canonical casing "OctoCat"`,
+		`"registry/missing/README.md": GitHub username "ghost" does not belong to any account`,
+		`"registry/coder-lab/README.md": namespace "coder-lab" does not match GitHub account "coderlab"`,
+		`"registry/acme/README.md": GitHub username "acme" belongs to an account of type "Organization" rather than a user`,
+	}
+	if len(phaseErr.errors) != len(expectedErrs) {
+		t.Errorf("Expected %d errors, got: %v", len(expectedErrs), phaseErr.errors)
+	}
+	for _, expected := range expectedErrs {
+		found := false
+		for _, e := range phaseErr.errors {
+			found = found || strings.Contains(e.Error(), expected)
+		}
+		if !found {
+			t.Errorf("Expected error containing %q, got: %v", expected, phaseErr.errors)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"image"
+	_ "image/jpeg" // Registers the JPEG decoder for image.DecodeConfig.
+	_ "image/png"  // Registers the PNG decoder for image.DecodeConfig.
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// maxEmbeddedRasterBytes is the largest decoded size allowed for a single raster image embedded in an SVG icon.
+	// Icons are rendered at small sizes, and large embedded bitmaps bloat every page of the Registry site that shows
+	// them.
+	maxEmbeddedRasterBytes = 64 * 1024
+
+	// minRasterIconSize is the smallest width and height allowed for PNG and JPEG icons, so that they still look
+	// sharp on high-density displays.
+	minRasterIconSize = 64
+)
+
+var (
+	supportedIconFileFormats = []string{".svg", ".png", ".jpg", ".jpeg"}
+
+	// iconReferenceRe matches references to icon files, either as relative paths into the .icons directory (used by
+	// README frontmatter) or as paths served by Coder under /icon/ (used by Terraform resources).
+	iconReferenceRe = regexp.MustCompile(`(?:\.icons|/icon)/([a-zA-Z0-9._-]+)`)
+
+	// Matches a base64-encoded raster image inside a data URL.
+	embeddedRasterRe = regexp.MustCompile(`^data:image/(png|jpe?g|gif|webp);base64,`)
+)
+
+// grandfatheredOversizedIcons lists SVG icons that embed raster images larger than maxEmbeddedRasterBytes and were
+// added before the limit existed. They are exempt until someone replaces them with real vector artwork. Do not add
+// new entries.
+var grandfatheredOversizedIcons = []string{
+	"antigravity.svg",
+	"cursor.svg",
+}
+
+// validateSvgIcon validates that an SVG icon is well-formed, has a viewBox so that it scales correctly, and cannot
+// load scripts or external resources when served from the Registry site.
+func validateSvgIcon(fileName string, content []byte) []error {
+	var errs []error
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	foundRoot := false
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return append(errs, xerrors.Errorf("SVG is not well-formed XML: %v", err))
+		}
+
+		el, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if !foundRoot {
+			foundRoot = true
+			if el.Name.Local != "svg" {
+				errs = append(errs, xerrors.Errorf("SVG root element must be <svg>, found <%s>", el.Name.Local))
+			}
+			hasViewBox := slices.ContainsFunc(el.Attr, func(a xml.Attr) bool {
+				return a.Name.Local == "viewBox"
+			})
+			if !hasViewBox {
+				errs = append(errs, xerrors.New("SVG root element is missing a viewBox attribute"))
+			}
+		}
+
+		if strings.EqualFold(el.Name.Local, "script") {
+			errs = append(errs, xerrors.New("SVG must not contain <script> elements"))
+		}
+		for _, attr := range el.Attr {
+			if strings.HasPrefix(strings.ToLower(attr.Name.Local), "on") {
+				errs = append(errs, xerrors.Errorf("SVG must not contain event handler attribute %q", attr.Name.Local))
+				continue
+			}
+			if attr.Name.Local != "href" {
+				continue
+			}
+
+			href := strings.TrimSpace(attr.Value)
+			if strings.HasPrefix(href, "#") {
+				continue
+			}
+			if !strings.HasPrefix(href, "data:") {
+				errs = append(errs, xerrors.Errorf("SVG must not reference external resource %q", href))
+				continue
+			}
+			if err := validateEmbeddedRaster(fileName, href); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if !foundRoot {
+		errs = append(errs, xerrors.New("SVG does not contain any elements"))
+	}
+	return errs
+}
+
+func validateEmbeddedRaster(fileName string, dataURL string) error {
+	match := embeddedRasterRe.FindString(dataURL)
+	if match == "" || slices.Contains(grandfatheredOversizedIcons, fileName) {
+		return nil
+	}
+
+	// Embedded base64 data is frequently wrapped across multiple lines, which the decoder does not accept.
+	encoded := strings.Join(strings.Fields(strings.TrimPrefix(dataURL, match)), "")
+	decodedSize := base64.StdEncoding.DecodedLen(len(encoded))
+	if decodedSize > maxEmbeddedRasterBytes {
+		return xerrors.Errorf("SVG embeds a %d KB raster image, which exceeds the %d KB limit", decodedSize/1024, maxEmbeddedRasterBytes/1024)
+	}
+	return nil
+}
+
+// svgViewBoxSize returns the width and height declared by the viewBox of an SVG's root element.
+func svgViewBoxSize(content []byte) (width float64, height float64, ok bool) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return 0, 0, false
+		}
+		el, isStart := token.(xml.StartElement)
+		if !isStart {
+			continue
+		}
+		for _, attr := range el.Attr {
+			if attr.Name.Local != "viewBox" {
+				continue
+			}
+			fields := strings.FieldsFunc(attr.Value, func(r rune) bool {
+				return r == ' ' || r == ','
+			})
+			if len(fields) != 4 {
+				return 0, 0, false
+			}
+			width, wErr := strconv.ParseFloat(fields[2], 64)
+			height, hErr := strconv.ParseFloat(fields[3], 64)
+			if wErr != nil || hErr != nil {
+				return 0, 0, false
+			}
+			return width, height, true
+		}
+		return 0, 0, false
+	}
+}
+
+// validateRasterIcon validates that a PNG or JPEG icon is square and large enough to render sharply.
+func validateRasterIcon(content []byte) []error {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return []error{xerrors.Errorf("failed to decode image: %v", err)}
+	}
+
+	var errs []error
+	if cfg.Width != cfg.Height {
+		errs = append(errs, xerrors.Errorf("%s icon must be square (found %dx%d)", format, cfg.Width, cfg.Height))
+	}
+	if cfg.Width < minRasterIconSize || cfg.Height < minRasterIconSize {
+		errs = append(errs, xerrors.Errorf("%s icon must be at least %dx%d (found %dx%d)", format, minRasterIconSize, minRasterIconSize, cfg.Width, cfg.Height))
+	}
+	return errs
+}
+
+func validateIconFile(fileName string, content []byte) []error {
+	ext := strings.ToLower(path.Ext(fileName))
+	switch ext {
+	case ".svg":
+		return validateSvgIcon(fileName, content)
+	case ".png", ".jpg", ".jpeg":
+		return validateRasterIcon(content)
+	default:
+		return []error{xerrors.Errorf("unsupported icon file format %q (supported: %s)", ext, strings.Join(supportedIconFileFormats, ", "))}
+	}
+}
+
+// collectReferencedIcons returns the file names of every icon referenced by a README or Terraform file in the
+// registry.
+func collectReferencedIcons() (map[string]bool, error) {
+	referenced := map[string]bool{}
+	err := filepath.WalkDir(rootRegistryPath, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".coder" || d.Name() == "node_modules" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != "README.md" && !strings.HasSuffix(d.Name(), ".tf") {
+			return nil
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		for _, match := range iconReferenceRe.FindAllStringSubmatch(string(content), -1) {
+			referenced[match[1]] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return referenced, nil
+}
+
+// validateAllIcons validates every asset in the top-level .icons directory, and warns about icons that nothing in the
+// registry uses. Unused icons are only warnings, because they may still be loaded from outside of the registry.
+func validateAllIcons() error {
+	entries, err := os.ReadDir(rootIconsPath)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	var iconNames []string
+	for _, e := range entries {
+		iconPath := path.Join(rootIconsPath, e.Name())
+		if e.IsDir() {
+			errs = append(errs, xerrors.Errorf("%q: .icons must not contain sub-directories", iconPath))
+			continue
+		}
+
+		content, err := os.ReadFile(iconPath)
+		if err != nil {
+			errs = append(errs, addFilePathToError(iconPath, err))
+			continue
+		}
+		for _, err := range validateIconFile(e.Name(), content) {
+			errs = append(errs, addFilePathToError(iconPath, err))
+		}
+		iconNames = append(iconNames, e.Name())
+	}
+	if len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseIcons,
+			errors: errs,
+		}
+	}
+	logger.Info(context.Background(), "processed all icon assets as valid", "num_icons", len(iconNames))
+
+	referenced, err := collectReferencedIcons()
+	if err != nil {
+		return err
+	}
+	for _, name := range iconNames {
+		if referenced[name] {
+			continue
+		}
+		logger.Warn(context.Background(), xerrors.Errorf("%q: icon is not referenced by any README or Terraform file in the registry", path.Join(rootIconsPath, name)).Error())
+	}
+	return nil
+}
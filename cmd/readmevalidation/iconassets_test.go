@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func encodeTestPNG(t *testing.T, width int, height int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestValidateIconFile(t *testing.T) {
+	t.Parallel()
+
+	oversizedRaster := base64.StdEncoding.EncodeToString(make([]byte, maxEmbeddedRasterBytes+1))
+	testCases := []struct {
+		name        string
+		fileName    string
+		content     []byte
+		expectedErr string
+	}{
+		{
+			name:     "valid SVG",
+			fileName: "valid.svg",
+			content:  []byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24"><use href="#a"/></svg>`),
+		},
+		{
+			name:        "malformed SVG",
+			fileName:    "malformed.svg",
+			content:     []byte(`<svg viewBox="0 0 24 24"><path></svg>`),
+			expectedErr: "not well-formed XML",
+		},
+		{
+			name:        "SVG without viewBox",
+			fileName:    "no-viewbox.svg",
+			content:     []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="24" height="24"></svg>`),
+			expectedErr: "missing a viewBox",
+		},
+		{
+			name:        "SVG with script",
+			fileName:    "script.svg",
+			content:     []byte(`<svg viewBox="0 0 24 24"><script>alert(1)</script></svg>`),
+			expectedErr: "<script>",
+		},
+		{
+			name:        "SVG with external href",
+			fileName:    "external.svg",
+			content:     []byte(`<svg xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 24 24"><image xlink:href="https://example.com/a.png"/></svg>`),
+			expectedErr: "external resource",
+		},
+		{
+			name:        "SVG with oversized raster",
+			fileName:    "oversized.svg",
+			content:     []byte(`<svg viewBox="0 0 24 24"><image href="data:image/png;base64,` + oversizedRaster + `"/></svg>`),
+			expectedErr: "exceeds the 64 KB limit",
+		},
+		{
+			name:     "valid PNG",
+			fileName: "valid.png",
+			content:  encodeTestPNG(t, 128, 128),
+		},
+		{
+			name:        "non-square PNG",
+			fileName:    "wide.png",
+			content:     encodeTestPNG(t, 256, 128),
+			expectedErr: "must be square",
+		},
+		{
+			name:        "undersized PNG",
+			fileName:    "small.png",
+			content:     encodeTestPNG(t, 16, 16),
+			expectedErr: "must be at least 64x64",
+		},
+		{
+			name:        "unsupported format",
+			fileName:    "icon.webp",
+			content:     []byte{},
+			expectedErr: "unsupported icon file format",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			errs := validateIconFile(tc.fileName, tc.content)
+			if tc.expectedErr == "" {
+				for _, e := range errs {
+					t.Errorf("Unexpected validation error: %v", e)
+				}
+				return
+			}
+
+			found := false
+			for _, e := range errs {
+				found = found || strings.Contains(e.Error(), tc.expectedErr)
+			}
+			if !found {
+				t.Errorf("Expected error containing %q, got: %v", tc.expectedErr, errs)
+			}
+		})
+	}
+}
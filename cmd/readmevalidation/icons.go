@@ -0,0 +1,163 @@
+package main
+
+import (
+	_ "embed"
+	"errors"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/agext/levenshtein"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"golang.org/x/xerrors"
+)
+
+// coderIconPathPrefix is the path prefix that Coder serves its bundled icons from. Terraform resources can reference
+// either one of Coder's built-in icons or any icon in this repo's top-level .icons directory through it.
+const coderIconPathPrefix = "/icon/"
+
+// coderEmojiPathPrefix is the path prefix that Coder serves emoji images from. File names are the lowercase hex code
+// points of the emoji joined by hyphens, e.g. "/emojis/1f1fa-1f1f8.png".
+const coderEmojiPathPrefix = "/emojis/"
+
+// maxIconSuggestionDistance is the largest edit distance between a missing icon name and a known one for the known
+// one to be suggested as a probable typo fix.
+const maxIconSuggestionDistance = 3
+
+var coderEmojiPathRe = regexp.MustCompile(`^/emojis/[0-9a-f]+(-[0-9a-f]+)*\.png$`)
+
+// trustedIconURLPrefixes lists the absolute URL prefixes that Terraform resources may load icons from. Everything
+// else should be added to the top-level .icons directory instead, so that workspaces never depend on third-party
+// hosts to render.
+var trustedIconURLPrefixes = []string{
+	"https://registry.coder.com/",
+	"https://raw.githubusercontent.com/coder/",
+}
+
+// terraformIconResourceTypes lists the Coder resources whose icon attributes are collected from Terraform files.
+// coder_parameter data sources are validated as part of validateCoderParameter instead.
+var terraformIconResourceTypes = []string{"coder_app", "coder_script", "coder_agent"}
+
+//go:embed builtinicons.txt
+var builtinIconsFile string
+
+// builtinCoderIcons lists the file names of the icons that Coder serves under /icon/ out of the box.
+var builtinCoderIcons = parseIconList(builtinIconsFile)
+
+// parseIconList parses a newline-separated list of icon file names, skipping blank lines and # comments.
+func parseIconList(text string) []string {
+	var icons []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		icons = append(icons, line)
+	}
+	return icons
+}
+
+// validateCoderIconPath validates that an icon path served by Coder (e.g. "/icon/code.svg") points at an icon that
+// will actually exist, either because Coder ships it or because it lives in this repo's .icons directory. Paths that
+// are not under /icon/ are ignored.
+func validateCoderIconPath(iconPath string) error {
+	name, ok := strings.CutPrefix(iconPath, coderIconPathPrefix)
+	if !ok {
+		return nil
+	}
+	if name == "" || strings.Contains(name, "/") {
+		return xerrors.Errorf("icon path %q must reference a single file directly under %q", iconPath, coderIconPathPrefix)
+	}
+	if slices.Contains(builtinCoderIcons, name) {
+		return nil
+	}
+
+	if _, err := os.Stat(path.Join(rootIconsPath, name)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			if suggestion := suggestIconName(name); suggestion != "" {
+				return xerrors.Errorf("icon path %q is neither a built-in Coder icon nor a file in the top-level .icons directory (did you mean %q?)", iconPath, coderIconPathPrefix+suggestion)
+			}
+			return xerrors.Errorf("icon path %q is neither a built-in Coder icon nor a file in the top-level .icons directory", iconPath)
+		}
+		return xerrors.Errorf("error checking icon file for %q: %v", iconPath, err)
+	}
+	return nil
+}
+
+// suggestIconName returns the known icon name closest to a missing one, or an empty string if nothing is close enough
+// to plausibly be a typo.
+func suggestIconName(name string) string {
+	candidates := slices.Clone(builtinCoderIcons)
+	if entries, err := os.ReadDir(rootIconsPath); err == nil {
+		for _, e := range entries {
+			candidates = append(candidates, e.Name())
+		}
+	}
+
+	best := ""
+	bestDistance := maxIconSuggestionDistance + 1
+	for _, c := range candidates {
+		if d := levenshtein.Distance(name, c, nil); d < bestDistance {
+			best = c
+			bestDistance = d
+		}
+	}
+	return best
+}
+
+// validateTerraformIcon validates the value of an icon attribute in a Terraform resource. Icons must be served by
+// Coder itself, either as an /icon/ path or an /emojis/ path, or come from a trusted host.
+func validateTerraformIcon(icon string) error {
+	switch {
+	case strings.HasPrefix(icon, coderIconPathPrefix):
+		return validateCoderIconPath(icon)
+	case strings.HasPrefix(icon, coderEmojiPathPrefix):
+		if !coderEmojiPathRe.MatchString(icon) {
+			return xerrors.Errorf("emoji icon path %q must have the form %q", icon, coderEmojiPathPrefix+"<hex code points>.png")
+		}
+		return nil
+	case strings.HasPrefix(icon, "http://") || strings.HasPrefix(icon, "https://"):
+		u, err := url.Parse(icon)
+		if err != nil {
+			return xerrors.Errorf("icon URL %q is not valid: %v", icon, err)
+		}
+		if !slices.ContainsFunc(trustedIconURLPrefixes, func(prefix string) bool {
+			return strings.HasPrefix(icon, prefix)
+		}) {
+			return xerrors.Errorf("icon URL %q is loaded from untrusted host %q (add the icon to the top-level .icons directory and use %q instead)", icon, u.Host, coderIconPathPrefix+path.Base(u.Path))
+		}
+		return nil
+	default:
+		return xerrors.Errorf("icon %q must be a %q path, a %q path, or a URL from a trusted host", icon, coderIconPathPrefix, coderEmojiPathPrefix)
+	}
+}
+
+// validateTerraformIcons validates every literal icon attribute set on a Coder resource, including attributes of
+// nested blocks.
+func validateTerraformIcons(tf coderResourceTerraform) []error {
+	var errs []error
+	var visitBody func(body *hclsyntax.Body)
+	visitBody = func(body *hclsyntax.Body) {
+		if iconAttr, ok := body.Attributes["icon"]; ok {
+			// Icons built from expressions (e.g. variables that default to an icon path) cannot be checked statically.
+			if icon, isLiteral := literalString(iconAttr.Expr); isLiteral {
+				if err := validateTerraformIcon(icon); err != nil {
+					errs = append(errs, addRangeToError(iconAttr.SrcRange, err))
+				}
+			}
+		}
+		for _, b := range body.Blocks {
+			visitBody(b.Body)
+		}
+	}
+
+	for _, resourceType := range terraformIconResourceTypes {
+		for _, b := range tf.blocks("resource", resourceType) {
+			visitBody(b.block.Body)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateTerraformIcon(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		icon        string
+		expectedErr string
+	}{
+		{icon: "/icon/code.svg"},
+		{icon: "/emojis/1f1fa-1f1f8.png"},
+		{icon: "https://registry.coder.com/icon/code.svg"},
+		{icon: "/icon/vscod.svg", expectedErr: `did you mean "/icon/vscode.svg"?`},
+		{icon: "/icon/nested/code.svg", expectedErr: "must reference a single file"},
+		{icon: "/emojis/1F4BE.png", expectedErr: "emoji icon path"},
+		{icon: "https://cdn.example.com/icon.png", expectedErr: `untrusted host "cdn.example.com"`},
+		{icon: "icon/code.svg", expectedErr: "must be a"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.icon, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateTerraformIcon(tc.icon)
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected validation error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("Expected error containing %q, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateTerraformIcons(t *testing.T) {
+	t.Parallel()
+
+	tf := parseTestTerraform(t, `
+resource "coder_app" "app" {
+  icon = "https://example.com/app.svg"
+}
+
+resource "coder_script" "script" {
+  icon = var.icon
+}
+
+resource "docker_container" "workspace" {
+  icon = "https://example.com/ignored.svg"
+}`)
+
+	errs := validateTerraformIcons(tf)
+	if len(errs) != 1 {
+		t.Fatalf("Expected exactly one error, got: %v", errs)
+	}
+	if !strings.Contains(errs[0].Error(), `main.tf:3": icon URL "https://example.com/app.svg"`) {
+		t.Errorf("Unexpected error: %v", errs[0])
+	}
+}
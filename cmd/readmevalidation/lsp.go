@@ -0,0 +1,816 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// maxLSPMessageBytes is the largest JSON-RPC message the server accepts on stdin.
+	maxLSPMessageBytes = 16 * 1024 * 1024
+
+	lspSeverityError   = 1
+	lspSeverityWarning = 2
+
+	lspTextDocumentSyncFull = 1
+
+	lspCompletionKindProperty = 10
+	lspCompletionKindValue    = 12
+	lspCompletionKindFile     = 17
+
+	lspDiagnosticSource = "readmevalidation"
+)
+
+var (
+	// lspErrorLineRe matches the 1-based line number that frontmatter decoding errors are prefixed with.
+	lspErrorLineRe = regexp.MustCompile(`\bline (\d+): `)
+	// lspModuleSourceRe matches the source of a registry module in a Terraform usage block.
+	lspModuleSourceRe = regexp.MustCompile(`^\s*source\s*=\s*"registry\.coder\.com/([^/"]+)/([^/"]+)/coder"`)
+	// lspAttributeRe matches a Terraform attribute assignment at the start of a line.
+	lspAttributeRe = regexp.MustCompile(`^(\s*)([A-Za-z_][\w-]*)\s*=`)
+	// lspFrontmatterKeyRe matches a top-level key in frontmatter, along with the space after its colon.
+	lspFrontmatterKeyRe = regexp.MustCompile(`^([\w-]+):\s*`)
+	// lspListItemRe matches the start of an item in a YAML block list.
+	lspListItemRe = regexp.MustCompile(`^\s+-\s*`)
+)
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspTextEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+// lspFix is a quick fix for a diagnostic. It travels to the client in the data field of the diagnostic, which clients
+// send back unchanged when they ask for code actions.
+type lspFix struct {
+	Title string      `json:"title"`
+	Edit  lspTextEdit `json:"edit"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+	Data     *lspFix  `json:"data,omitempty"`
+}
+
+type lspCompletionItem struct {
+	Label         string      `json:"label"`
+	Kind          int         `json:"kind"`
+	Documentation string      `json:"documentation,omitempty"`
+	TextEdit      lspTextEdit `json:"textEdit"`
+}
+
+type lspMarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type lspHover struct {
+	Contents lspMarkupContent `json:"contents"`
+	Range    lspRange         `json:"range"`
+}
+
+type lspWorkspaceEdit struct {
+	Changes map[string][]lspTextEdit `json:"changes"`
+}
+
+type lspCodeAction struct {
+	Title       string           `json:"title"`
+	Kind        string           `json:"kind"`
+	Diagnostics []lspDiagnostic  `json:"diagnostics"`
+	IsPreferred bool             `json:"isPreferred"`
+	Edit        lspWorkspaceEdit `json:"edit"`
+}
+
+type lspNotification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+// lspTextDocumentParams holds the fields shared by every request and notification about a single document.
+type lspTextDocumentParams struct {
+	TextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	} `json:"textDocument"`
+	Position       lspPosition `json:"position"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+	Context struct {
+		Diagnostics []lspDiagnostic `json:"diagnostics"`
+	} `json:"context"`
+}
+
+// utf16Length returns the length of a string in UTF-16 code units, which is how LSP counts characters.
+func utf16Length(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+// byteOffset converts an LSP character offset within a line into a byte offset, clamped to the end of the line.
+func byteOffset(line string, character int) int {
+	units := 0
+	for i, r := range line {
+		if units >= character {
+			return i
+		}
+		units += utf16.RuneLen(r)
+	}
+	return len(line)
+}
+
+// lspDocument is a registry README open in the editor. filePath is relative to the root of the repo, like every
+// path the validators work with.
+type lspDocument struct {
+	uri      string
+	filePath string
+	text     string
+	lines    []string
+}
+
+func newLSPDocument(uri string, filePath string, text string) lspDocument {
+	return lspDocument{uri: uri, filePath: filePath, text: text, lines: strings.Split(text, "\n")}
+}
+
+// lineRange returns the range covering the whole of a line.
+func (d lspDocument) lineRange(line int) lspRange {
+	line = min(max(line, 0), len(d.lines)-1)
+	return lspRange{
+		Start: lspPosition{Line: line},
+		End:   lspPosition{Line: line, Character: utf16Length(d.lines[line])},
+	}
+}
+
+// frontmatter parses the frontmatter of the document. lineOffset converts the 1-based lines of the returned nodes into
+// 0-based document lines.
+func (d lspDocument) frontmatter() (root *yaml.Node, lineOffset int, ok bool) {
+	fm, startLine, _, err := splitFrontmatter(d.text)
+	if err != nil {
+		return nil, 0, false
+	}
+	doc := yaml.Node{}
+	if err := yaml.Unmarshal([]byte(fm), &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, 0, false
+	}
+	return doc.Content[0], startLine - 2, true
+}
+
+// yamlMappingValue returns the key and value nodes of a key in a mapping node, or nil if the key isn't set.
+func yamlMappingValue(n *yaml.Node, key string) (keyNode *yaml.Node, valueNode *yaml.Node) {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i], n.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// nodeRange returns the range of a scalar node, including its quotes.
+func nodeRange(n *yaml.Node, lineOffset int) lspRange {
+	length := utf16Length(n.Value)
+	if n.Style == yaml.SingleQuotedStyle || n.Style == yaml.DoubleQuotedStyle {
+		length += 2
+	}
+	start := lspPosition{Line: n.Line + lineOffset, Character: n.Column - 1}
+	return lspRange{Start: start, End: lspPosition{Line: start.Line, Character: start.Character + length}}
+}
+
+// diagnostic converts a validation error into a diagnostic. Errors that name a line are placed on it, and errors that
+// mention a frontmatter key are placed on that key. Other errors about the frontmatter are placed on its opening fence,
+// and everything else on the first line of the body.
+func (d lspDocument) diagnostic(err error, severity int) lspDiagnostic {
+	msg := err.Error()
+	for prefix := fmt.Sprintf("%q: ", d.filePath); strings.HasPrefix(msg, prefix); {
+		msg = strings.TrimPrefix(msg, prefix)
+	}
+
+	if match := lspErrorLineRe.FindStringSubmatchIndex(msg); match != nil {
+		n, _ := strconv.Atoi(msg[match[2]:match[3]])
+		msg = msg[:match[0]] + msg[match[1]:]
+		return lspDiagnostic{Range: d.lineRange(n - 1), Severity: severity, Source: lspDiagnosticSource, Message: msg}
+	}
+
+	openIdx, closeIdx, fenceErr := frontmatterFences(d.lines)
+	line := 0
+	if fenceErr == nil {
+		line = openIdx
+		if !strings.Contains(msg, "frontmatter") {
+			line = slices.IndexFunc(d.lines[closeIdx+1:], func(l string) bool { return strings.TrimSpace(l) != "" }) + closeIdx + 1
+		}
+	}
+	if root, lineOffset, ok := d.frontmatter(); ok {
+		longest := ""
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			key := root.Content[i]
+			if strings.Contains(msg, key.Value) && len(key.Value) > len(longest) {
+				longest = key.Value
+				line = key.Line + lineOffset
+			}
+		}
+	}
+	return lspDiagnostic{Range: d.lineRange(line), Severity: severity, Source: lspDiagnosticSource, Message: msg}
+}
+
+// lspServer is a language server for the READMEs in the registry. It publishes the same errors and warnings as the
+// validator whenever a README is opened or changed, and helps write frontmatter with completions and quick fixes.
+type lspServer struct {
+	vocab tagVocabulary
+	// registryDir is where the modules referenced by Terraform usage blocks are looked up.
+	registryDir string
+	documents   map[string]lspDocument
+	out         io.Writer
+}
+
+func newLSPServer(vocab tagVocabulary) *lspServer {
+	return &lspServer{vocab: vocab, registryDir: rootRegistryPath, documents: map[string]lspDocument{}}
+}
+
+// registryReadmePath converts a document URI into a path relative to the working directory, which is the root of the
+// repo. It only succeeds for READMEs in the registry.
+func registryReadmePath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", false
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(wd, filepath.FromSlash(u.Path))
+	if err != nil {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.HasPrefix(rel, path.Clean(rootRegistryPath)+"/") || path.Base(rel) != "README.md" {
+		return "", false
+	}
+	return rel, true
+}
+
+// diagnose runs every validator that applies to a single README.
+func (s *lspServer) diagnose(d lspDocument) []lspDiagnostic {
+	format, err := frontmatterFormatForPath(d.filePath)
+	if err != nil {
+		return nil
+	}
+	rm := readme{filePath: d.filePath, rawText: d.text}
+
+	var errs, warnings []error
+	parsed := false
+	switch format.frontmatter {
+	case reflect.TypeFor[coderResourceFrontmatter]():
+		resourceType := path.Base(path.Dir(path.Dir(d.filePath)))
+		resource, parseErrs := parseCoderResourceReadme(resourceType, rm)
+		if len(parseErrs) != 0 {
+			errs = parseErrs
+			break
+		}
+		parsed = true
+		if resourceType == "modules" {
+			errs = validateCoderModuleReadme(resource)
+		} else {
+			errs = validateCoderTemplateReadme(resource)
+		}
+		if tf, tfErrs := parseCoderResourceTerraform(resourceType, path.Dir(d.filePath)); len(tfErrs) == 0 {
+			osErrs, osWarnings := validateSupportedOS(resource.frontmatter.OperatingSystems, inferOSSupport(tf))
+			errs = append(errs, osErrs...)
+			warnings = append(warnings, osWarnings...)
+		}
+	case reflect.TypeFor[contributorProfileFrontmatter]():
+		profile, parseErrs := parseContributorProfile(rm)
+		if len(parseErrs) != 0 {
+			errs = parseErrs
+			break
+		}
+		errs = validateContributorReadme(profile)
+	case reflect.TypeFor[coderSkillsFrontmatter]():
+		skills, parseErrs := parseCoderSkillsReadme(rm)
+		if len(parseErrs) != 0 {
+			errs = parseErrs
+			break
+		}
+		errs = validateCoderSkillsFrontmatter(d.filePath, skills.frontmatter)
+	}
+
+	diagnostics := []lspDiagnostic{}
+	for _, err := range errs {
+		diagnostics = append(diagnostics, d.diagnostic(err, lspSeverityError))
+	}
+	for _, w := range warnings {
+		diagnostics = append(diagnostics, d.diagnostic(w, lspSeverityWarning))
+	}
+	if parsed {
+		diagnostics = append(diagnostics, s.tagDiagnostics(d)...)
+		if fix := d.iconFix(); fix != nil {
+			for i := range diagnostics {
+				if diagnostics[i].Range.Start.Line == fix.Edit.Range.Start.Line {
+					diagnostics[i].Data = fix
+				}
+			}
+		}
+	}
+	slices.SortStableFunc(diagnostics, func(a, b lspDiagnostic) int { return a.Range.Start.Line - b.Range.Start.Line })
+	return diagnostics
+}
+
+// tagDiagnostics checks every tag of a module or template against the tag vocabulary, with a quick fix for tags that
+// aren't normalized, synonyms, deprecated tags that have a replacement, and likely typos.
+func (s *lspServer) tagDiagnostics(d lspDocument) []lspDiagnostic {
+	if s.vocab.Tags == nil {
+		return nil
+	}
+	root, lineOffset, ok := d.frontmatter()
+	if !ok {
+		return nil
+	}
+	_, tagsNode := yamlMappingValue(root, "tags")
+	if tagsNode == nil || tagsNode.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	var diagnostics []lspDiagnostic
+	synonyms := s.vocab.synonyms()
+	for _, item := range tagsNode.Content {
+		tag := item.Value
+		errs, warnings := validateTagsAgainstVocabulary(s.vocab, []string{tag})
+		normalized := normalizeTag(tag)
+		replacement, isSynonym := synonyms[normalized]
+		if dep, ok := s.vocab.Deprecated[normalized]; ok {
+			replacement = dep.Replacement
+		} else if _, ok := s.vocab.Tags[normalized]; ok {
+			replacement = normalized
+		} else if !isSynonym {
+			replacement = s.vocab.suggest(normalized)
+		}
+		var fix *lspFix
+		if replacement != "" {
+			fix = &lspFix{
+				Title: fmt.Sprintf("Replace tag %q with %q", tag, replacement),
+				Edit:  lspTextEdit{Range: nodeRange(item, lineOffset), NewText: replacement},
+			}
+		}
+		for _, err := range errs {
+			diagnostics = append(diagnostics, lspDiagnostic{Range: nodeRange(item, lineOffset), Severity: lspSeverityError, Source: lspDiagnosticSource, Message: err.Error(), Data: fix})
+		}
+		for _, w := range warnings {
+			diagnostics = append(diagnostics, lspDiagnostic{Range: nodeRange(item, lineOffset), Severity: lspSeverityWarning, Source: lspDiagnosticSource, Message: w.Error(), Data: fix})
+		}
+	}
+	return diagnostics
+}
+
+// iconFix returns a quick fix for an icon path that names an existing icon, but doesn't reference it through the
+// top-level .icons directory the way the registry requires.
+func (d lspDocument) iconFix() *lspFix {
+	root, lineOffset, ok := d.frontmatter()
+	if !ok {
+		return nil
+	}
+	_, iconNode := yamlMappingValue(root, "icon")
+	if iconNode == nil || iconNode.Kind != yaml.ScalarNode || iconNode.Value == "" {
+		return nil
+	}
+	rel, err := filepath.Rel(path.Dir(d.filePath), rootIconsPath)
+	if err != nil {
+		return nil
+	}
+	expected := path.Join(filepath.ToSlash(rel), path.Base(iconNode.Value))
+	if expected == iconNode.Value {
+		return nil
+	}
+	if _, err := os.Stat(path.Join(rootIconsPath, path.Base(iconNode.Value))); err != nil {
+		return nil
+	}
+	return &lspFix{
+		Title: fmt.Sprintf("Reference the icon as %q", expected),
+		Edit:  lspTextEdit{Range: nodeRange(iconNode, lineOffset), NewText: expected},
+	}
+}
+
+// completionValues returns the values that can be completed for a frontmatter key.
+func (s *lspServer) completionValues(d lspDocument, key string) (values []string, kind int) {
+	switch key {
+	case "tags":
+		for tag := range s.vocab.Tags {
+			values = append(values, tag)
+		}
+		slices.Sort(values)
+		return values, lspCompletionKindValue
+	case "supported_os":
+		return operatingSystems, lspCompletionKindValue
+	case "status":
+		return validContributorStatuses, lspCompletionKindValue
+	case "icon":
+		rel, err := filepath.Rel(path.Dir(d.filePath), rootIconsPath)
+		if err != nil {
+			return nil, 0
+		}
+		entries, err := os.ReadDir(rootIconsPath)
+		if err != nil {
+			return nil, 0
+		}
+		for _, e := range entries {
+			if !e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+				values = append(values, path.Join(filepath.ToSlash(rel), e.Name()))
+			}
+		}
+		return values, lspCompletionKindFile
+	}
+	return nil, 0
+}
+
+// complete returns the completions at a position in the frontmatter: missing keys at the start of a line, and the
+// values of keys with a fixed set of values after them.
+func (s *lspServer) complete(d lspDocument, pos lspPosition) []lspCompletionItem {
+	items := []lspCompletionItem{}
+	openIdx, closeIdx, err := frontmatterFences(d.lines)
+	if err != nil || pos.Line <= openIdx || pos.Line >= closeIdx {
+		return items
+	}
+	format, err := frontmatterFormatForPath(d.filePath)
+	if err != nil {
+		return items
+	}
+	line := d.lines[pos.Line]
+	before := line[:byteOffset(line, pos.Character)]
+
+	if !strings.Contains(before, ":") && strings.TrimLeft(before, " -") == before {
+		present := map[string]bool{}
+		for _, l := range d.lines[openIdx+1 : closeIdx] {
+			if match := lspFrontmatterKeyRe.FindStringSubmatch(l); match != nil {
+				present[match[1]] = true
+			}
+		}
+		editRange := lspRange{Start: lspPosition{Line: pos.Line}, End: pos}
+		for _, field := range reflect.VisibleFields(format.frontmatter) {
+			key := yamlFieldName(field)
+			if key == "" || present[key] {
+				continue
+			}
+			items = append(items, lspCompletionItem{
+				Label:         key,
+				Kind:          lspCompletionKindProperty,
+				Documentation: field.Tag.Get("jsonschema_description"),
+				TextEdit:      lspTextEdit{Range: editRange, NewText: key + ": "},
+			})
+		}
+		return items
+	}
+
+	key, valueStart := "", 0
+	if match := lspFrontmatterKeyRe.FindStringSubmatch(before); match != nil {
+		key, valueStart = match[1], len(match[0])
+	} else if match := lspListItemRe.FindString(before); match != "" {
+		valueStart = len(match)
+		for i := pos.Line - 1; i > openIdx; i-- {
+			if keyMatch := lspFrontmatterKeyRe.FindStringSubmatch(d.lines[i]); keyMatch != nil {
+				key = keyMatch[1]
+				break
+			}
+		}
+	}
+	values, kind := s.completionValues(d, key)
+	if kind != lspCompletionKindFile {
+		// Values in flow lists are completed one at a time.
+		valueStart = max(valueStart, strings.LastIndexAny(before, "[, ")+1)
+	}
+	editRange := lspRange{Start: lspPosition{Line: pos.Line, Character: utf16Length(before[:valueStart])}, End: pos}
+	for _, value := range values {
+		items = append(items, lspCompletionItem{
+			Label:    value,
+			Kind:     kind,
+			TextEdit: lspTextEdit{Range: editRange, NewText: value},
+		})
+	}
+	return items
+}
+
+// variableHoverMarkdown describes a module input variable for a hover.
+func variableHoverMarkdown(moduleID string, v terraformVariable) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s**", v.Name)
+	if v.Type != "" {
+		fmt.Fprintf(&b, ": `%s`", v.Type)
+	}
+	if v.Required {
+		b.WriteString(" (required)")
+	}
+	fmt.Fprintf(&b, "\n\nInput variable of the `%s` module.", moduleID)
+	if v.Description != "" {
+		b.WriteString("\n\n" + v.Description)
+	}
+	if v.Default != nil {
+		fmt.Fprintf(&b, "\n\nDefault: `%s`", v.Default)
+	}
+	if v.Sensitive {
+		b.WriteString("\n\nSensitive.")
+	}
+	return b.String()
+}
+
+// hover documents the module input variable under the cursor in a Terraform code block that uses a registry module.
+func (s *lspServer) hover(d lspDocument, pos lspPosition) *lspHover {
+	blockStart, blockEnd := -1, -1
+	isInCodeBlock := false
+	for i, line := range d.lines {
+		fence, ok := strings.CutPrefix(strings.TrimSpace(line), "```")
+		if !ok {
+			continue
+		}
+		if !isInCodeBlock {
+			isInCodeBlock = true
+			if slices.Contains([]string{"tf", "hcl", "terraform"}, strings.TrimSpace(fence)) {
+				blockStart = i
+			} else {
+				blockStart = -1
+			}
+			continue
+		}
+		isInCodeBlock = false
+		if blockStart != -1 && blockStart < pos.Line && pos.Line < i {
+			blockEnd = i
+			break
+		}
+	}
+	if blockEnd == -1 || pos.Line >= len(d.lines) {
+		return nil
+	}
+
+	namespace, name := "", ""
+	for _, line := range d.lines[blockStart+1 : blockEnd] {
+		if match := lspModuleSourceRe.FindStringSubmatch(line); match != nil {
+			namespace, name = match[1], match[2]
+			break
+		}
+	}
+	line := d.lines[pos.Line]
+	match := lspAttributeRe.FindStringSubmatchIndex(line)
+	if namespace == "" || match == nil {
+		return nil
+	}
+	cursor := byteOffset(line, pos.Character)
+	if cursor < match[4] || cursor > match[5] {
+		return nil
+	}
+
+	tf, errs := parseCoderResourceTerraform("modules", path.Join(s.registryDir, namespace, "modules", name))
+	if len(errs) != 0 {
+		return nil
+	}
+	variables := tf.variables()
+	idx := slices.IndexFunc(variables, func(v terraformVariable) bool { return v.Name == line[match[4]:match[5]] })
+	if idx == -1 {
+		return nil
+	}
+	return &lspHover{
+		Contents: lspMarkupContent{Kind: "markdown", Value: variableHoverMarkdown(namespace+"/"+name, variables[idx])},
+		Range: lspRange{
+			Start: lspPosition{Line: pos.Line, Character: utf16Length(line[:match[4]])},
+			End:   lspPosition{Line: pos.Line, Character: utf16Length(line[:match[5]])},
+		},
+	}
+}
+
+// formatting returns the edit that formats the frontmatter of a README, like the fmt command does.
+func (s *lspServer) formatting(d lspDocument) []lspTextEdit {
+	edits := []lspTextEdit{}
+	format, err := frontmatterFormatForPath(d.filePath)
+	if err != nil {
+		return edits
+	}
+	formatted, errs := frontmatterFormatter{tagSynonyms: s.vocab.synonyms()}.format(d.text, format)
+	if len(errs) != 0 || formatted == d.text {
+		return edits
+	}
+	last := len(d.lines) - 1
+	return append(edits, lspTextEdit{
+		Range:   lspRange{End: lspPosition{Line: last, Character: utf16Length(d.lines[last])}},
+		NewText: formatted,
+	})
+}
+
+// codeActions returns the quick fixes attached to the diagnostics the client asks about.
+func codeActions(uri string, diagnostics []lspDiagnostic) []lspCodeAction {
+	actions := []lspCodeAction{}
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Data == nil {
+			continue
+		}
+		actions = append(actions, lspCodeAction{
+			Title:       diagnostic.Data.Title,
+			Kind:        "quickfix",
+			Diagnostics: []lspDiagnostic{diagnostic},
+			IsPreferred: true,
+			Edit:        lspWorkspaceEdit{Changes: map[string][]lspTextEdit{uri: {diagnostic.Data.Edit}}},
+		})
+	}
+	return actions
+}
+
+func (s *lspServer) notify(method string, params any) error {
+	return writeLSPMessage(s.out, lspNotification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (s *lspServer) publishDiagnostics(uri string, diagnostics []lspDiagnostic) error {
+	return s.notify("textDocument/publishDiagnostics", map[string]any{"uri": uri, "diagnostics": diagnostics})
+}
+
+// handle processes a single request or notification and returns its result, or a JSON-RPC error.
+func (s *lspServer) handle(req jsonrpcRequest) (any, *jsonrpcError) {
+	params := lspTextDocumentParams{}
+	if len(req.Params) != 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &jsonrpcError{Code: jsonrpcInvalidParams, Message: err.Error()}
+		}
+	}
+	uri := params.TextDocument.URI
+	doc, isOpen := s.documents[uri]
+
+	switch req.Method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":           map[string]any{"openClose": true, "change": lspTextDocumentSyncFull},
+				"completionProvider":         map[string]any{"triggerCharacters": []string{" ", "[", ",", "/"}},
+				"hoverProvider":              true,
+				"codeActionProvider":         map[string]any{"codeActionKinds": []string{"quickfix"}},
+				"documentFormattingProvider": true,
+			},
+			"serverInfo": map[string]any{"name": "coder-registry", "version": "0.1.0"},
+		}, nil
+	case "shutdown":
+		return json.RawMessage("null"), nil
+	case "textDocument/didOpen", "textDocument/didChange", "textDocument/didSave":
+		filePath, ok := registryReadmePath(uri)
+		if !ok || (req.Method == "textDocument/didSave" && !isOpen) {
+			return nil, nil
+		}
+		text := params.TextDocument.Text
+		switch {
+		case len(params.ContentChanges) != 0:
+			text = params.ContentChanges[len(params.ContentChanges)-1].Text
+		case req.Method == "textDocument/didSave":
+			text = doc.text
+		}
+		doc = newLSPDocument(uri, filePath, text)
+		s.documents[uri] = doc
+		if err := s.publishDiagnostics(uri, s.diagnose(doc)); err != nil {
+			logger.Error(context.Background(), "failed to publish diagnostics", "uri", uri, "error", err.Error())
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		if isOpen {
+			delete(s.documents, uri)
+			if err := s.publishDiagnostics(uri, []lspDiagnostic{}); err != nil {
+				logger.Error(context.Background(), "failed to clear diagnostics", "uri", uri, "error", err.Error())
+			}
+		}
+		return nil, nil
+	case "textDocument/completion":
+		if !isOpen {
+			return []lspCompletionItem{}, nil
+		}
+		return s.complete(doc, params.Position), nil
+	case "textDocument/hover":
+		if !isOpen {
+			return json.RawMessage("null"), nil
+		}
+		if h := s.hover(doc, params.Position); h != nil {
+			return h, nil
+		}
+		return json.RawMessage("null"), nil
+	case "textDocument/codeAction":
+		return codeActions(uri, params.Context.Diagnostics), nil
+	case "textDocument/formatting":
+		if !isOpen {
+			return []lspTextEdit{}, nil
+		}
+		return s.formatting(doc), nil
+	default:
+		return nil, &jsonrpcError{Code: jsonrpcMethodNotFound, Message: "method not found: " + req.Method}
+	}
+}
+
+// readLSPMessage reads a single message framed by a Content-Length header. It returns io.EOF when the input ends
+// between messages.
+func readLSPMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" && length == -1 {
+				return nil, io.EOF
+			}
+			return nil, xerrors.Errorf("failed to read message header: %v", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, xerrors.Errorf("invalid Content-Length header %q", value)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, xerrors.New("message is missing a Content-Length header")
+	}
+	if length > maxLSPMessageBytes {
+		return nil, xerrors.Errorf("message of %d bytes exceeds the maximum of %d", length, maxLSPMessageBytes)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, xerrors.Errorf("failed to read message body: %v", err)
+	}
+	return body, nil
+}
+
+func writeLSPMessage(w io.Writer, msg any) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+// serve reads messages from in until it is closed or the client sends an exit notification, writing a response for
+// every request and publishing diagnostics for every README that is opened or changed.
+func (s *lspServer) serve(in io.Reader, out io.Writer) error {
+	s.out = out
+	reader := bufio.NewReader(in)
+	for {
+		body, err := readLSPMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		req := jsonrpcRequest{}
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := writeLSPMessage(out, jsonrpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &jsonrpcError{Code: jsonrpcParseError, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			return nil
+		}
+
+		result, rpcErr := s.handle(req)
+		if len(req.ID) == 0 {
+			continue
+		}
+		if err := writeLSPMessage(out, jsonrpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr}); err != nil {
+			return err
+		}
+	}
+}
+
+// runLSPCommand serves the registry READMEs as a language server over stdin and stdout. It must be started from the
+// root of the repo, like the validator itself. Logs go to stderr, since stdout is reserved for protocol messages.
+func runLSPCommand(_ []string) error {
+	logger = slog.Make(sloghuman.Sink(os.Stderr))
+	vocab, err := loadTagVocabulary()
+	if err != nil {
+		logger.Warn(context.Background(), "failed to load the tag vocabulary; tags will not be checked or completed", "error", err.Error())
+		vocab = tagVocabulary{}
+	}
+	logger.Info(context.Background(), "serving registry READMEs over LSP stdio")
+	return newLSPServer(vocab).serve(os.Stdin, os.Stdout)
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var testLSPVocabulary = tagVocabulary{
+	Tags: map[string][]string{
+		"ide":    {"editor"},
+		"web":    nil,
+		"docker": nil,
+	},
+	Deprecated: map[string]deprecatedTag{
+		"development": {Reason: "Too broad."},
+	},
+}
+
+const testLSPReadmePath = "registry/acme/modules/code-server/README.md"
+
+func TestLSPDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{
+			name:     "unknown key",
+			text:     "---\ndescription: VS Code in the browser\nicon: ../../../../.icons/code.svg\nauthor: someone\ntags: [ide]\n---\n\n# Code Server\n",
+			expected: []string{`3:1:detected unknown key "author"`},
+		},
+		{
+			name: "validators and tags",
+			text: "---\ndescription: \"\"\nicon: ../.icons/code.svg\ntags: [ide, editor, development, dokcer, Docker]\n---\n\n# Code Server\n",
+			expected: []string{
+				"6:1:did not find Terraform code block within h1 section",
+				"6:1:did not find paragraph within h1 section",
+				"1:1:frontmatter description cannot be empty",
+				`2:1:icon URL "../.icons/code.svg" must reference the top-level .icons directory`,
+				`3:2:tag "editor" is a synonym of "ide"|Replace tag "editor" with "ide"`,
+				`3:1:tag "development" is deprecated: Too broad.`,
+				`3:2:tag "dokcer" is not in the tag vocabulary (did you mean "docker"?)|Replace tag "dokcer" with "docker"`,
+				`3:2:tag "Docker" is not normalized (expected "docker")|Replace tag "Docker" with "docker"`,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := newLSPServer(testLSPVocabulary)
+			var got []string
+			for _, d := range server.diagnose(newLSPDocument("file:///"+testLSPReadmePath, testLSPReadmePath, tc.text)) {
+				summary := fmt.Sprintf("%d:%d:%s", d.Range.Start.Line, d.Severity, d.Message)
+				if d.Data != nil {
+					summary += "|" + d.Data.Title
+				}
+				got = append(got, summary)
+			}
+			if len(got) != len(tc.expected) {
+				t.Fatalf("expected %d diagnostics, got: %q", len(tc.expected), got)
+			}
+			for _, expected := range tc.expected {
+				prefix, fix, _ := strings.Cut(expected, "|")
+				if !slices.ContainsFunc(got, func(g string) bool {
+					return strings.HasPrefix(g, prefix) && (fix == "" || strings.HasSuffix(g, "|"+fix))
+				}) {
+					t.Errorf("expected a diagnostic matching %q, got: %q", expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLSPCompletion(t *testing.T) {
+	t.Parallel()
+
+	text := "---\ndescription: VS Code in the browser\ndis\ntags: [ide, d\nsupported_os:\n  - l\n---\n\n# Code Server\n"
+	doc := newLSPDocument("file:///"+testLSPReadmePath, testLSPReadmePath, text)
+	server := newLSPServer(testLSPVocabulary)
+
+	testCases := []struct {
+		name          string
+		pos           lspPosition
+		expected      []string
+		expectedStart int
+	}{
+		{name: "missing keys", pos: lspPosition{Line: 2, Character: 3}, expected: []string{"display_name: ", "icon: ", "verified: "}},
+		{name: "tags in a flow list", pos: lspPosition{Line: 3, Character: 13}, expected: []string{"docker", "ide", "web"}, expectedStart: 12},
+		{name: "operating systems in a block list", pos: lspPosition{Line: 5, Character: 5}, expected: operatingSystems, expectedStart: 4},
+		{name: "outside of the frontmatter", pos: lspPosition{Line: 8, Character: 2}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			items := server.complete(doc, tc.pos)
+			var got []string
+			for _, item := range items {
+				got = append(got, item.TextEdit.NewText)
+				if item.TextEdit.Range.Start.Character != tc.expectedStart {
+					t.Errorf("expected %q to replace from character %d, got %d", item.Label, tc.expectedStart, item.TextEdit.Range.Start.Character)
+				}
+			}
+			if !slices.Equal(got, tc.expected) {
+				t.Errorf("expected completions %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestLSPHover(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	moduleDir := path.Join(registryDir, "acme", "modules", "code-server")
+	if err := os.MkdirAll(moduleDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path.Join(moduleDir, "main.tf"), []byte(testModuleTerraform), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	server := newLSPServer(testLSPVocabulary)
+	server.registryDir = registryDir
+
+	text := "---\ndescription: x\n---\n\n# Code Server\n\n```tf\nmodule \"code-server\" {\n  source   = \"registry.coder.com/acme/code-server/coder\"\n  agent_id = coder_agent.main.id\n  port     = 8080\n}\n```\n\nport = 1\n"
+	doc := newLSPDocument("file:///"+testLSPReadmePath, testLSPReadmePath, text)
+
+	hover := server.hover(doc, lspPosition{Line: 9, Character: 4})
+	if hover == nil {
+		t.Fatal("expected a hover for agent_id")
+	}
+	if !strings.HasPrefix(hover.Contents.Value, "**agent_id**: `string` (required)") || !strings.Contains(hover.Contents.Value, "The ID of a Coder agent.") {
+		t.Errorf("unexpected hover content: %q", hover.Contents.Value)
+	}
+	if hover.Range.Start.Character != 2 || hover.Range.End.Character != 10 {
+		t.Errorf("expected the hover to cover agent_id, got %+v", hover.Range)
+	}
+	if hover := server.hover(doc, lspPosition{Line: 10, Character: 3}); hover == nil || !strings.Contains(hover.Contents.Value, "Default: `13337`") {
+		t.Errorf("expected the default of port in the hover, got %+v", hover)
+	}
+	for _, pos := range []lspPosition{{Line: 10, Character: 13}, {Line: 14, Character: 1}, {Line: 8, Character: 3}} {
+		if hover := server.hover(doc, pos); hover != nil {
+			t.Errorf("expected no hover at %+v, got %q", pos, hover.Contents.Value)
+		}
+	}
+}
+
+func TestLSPServe(t *testing.T) {
+	t.Parallel()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := "file://" + path.Join(wd, testLSPReadmePath)
+	text := "---\ndescription: VS Code in the browser\nicon: ../../../../.icons/code.svg\ntags: [editor]\n---\n\n# Code Server\n"
+
+	var in bytes.Buffer
+	messages := []any{
+		map[string]any{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": map[string]any{}},
+		map[string]any{"jsonrpc": "2.0", "method": "initialized", "params": map[string]any{}},
+		map[string]any{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": map[string]any{"textDocument": map[string]any{"uri": uri, "text": text}}},
+		map[string]any{"jsonrpc": "2.0", "id": 2, "method": "textDocument/formatting", "params": map[string]any{"textDocument": map[string]any{"uri": uri}}},
+		map[string]any{"jsonrpc": "2.0", "id": 3, "method": "shutdown"},
+		map[string]any{"jsonrpc": "2.0", "method": "exit"},
+		map[string]any{"jsonrpc": "2.0", "id": 4, "method": "shutdown"},
+	}
+	for _, msg := range messages {
+		if err := writeLSPMessage(&in, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := newLSPServer(testLSPVocabulary).serve(&in, &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	type message struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params struct {
+			URI         string          `json:"uri"`
+			Diagnostics []lspDiagnostic `json:"diagnostics"`
+		} `json:"params"`
+		Result json.RawMessage `json:"result"`
+	}
+	var got []message
+	reader := bufio.NewReader(&out)
+	for {
+		body, err := readLSPMessage(reader)
+		if err != nil {
+			break
+		}
+		msg := message{}
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatalf("Failed to decode message: %v", err)
+		}
+		got = append(got, msg)
+	}
+
+	// Nothing after the exit notification is processed.
+	if len(got) != 4 {
+		t.Fatalf("Expected 4 messages, got %d: %+v", len(got), got)
+	}
+	if string(got[0].ID) != "1" || !strings.Contains(string(got[0].Result), `"hoverProvider":true`) {
+		t.Errorf("Expected initialize result, got: %+v", got[0])
+	}
+	if got[1].Method != "textDocument/publishDiagnostics" || got[1].Params.URI != uri || !slices.ContainsFunc(got[1].Params.Diagnostics, func(d lspDiagnostic) bool {
+		return strings.Contains(d.Message, `tag "editor" is a synonym of "ide"`)
+	}) {
+		t.Errorf("Expected diagnostics for the opened README, got: %+v", got[1])
+	}
+	if string(got[2].ID) != "2" || !strings.Contains(string(got[2].Result), `tags: [ide]`) {
+		t.Errorf("Expected a formatting edit, got: %s", got[2].Result)
+	}
+	if string(got[3].ID) != "3" || string(got[3].Result) != "null" {
+		t.Errorf("Expected a null shutdown result, got: %+v", got[3])
+	}
+}
+
+func TestCodeActions(t *testing.T) {
+	t.Parallel()
+
+	fix := &lspFix{Title: `Replace tag "editor" with "ide"`, Edit: lspTextEdit{NewText: "ide"}}
+	actions := codeActions("file:///README.md", []lspDiagnostic{{Message: "no fix"}, {Message: "synonym", Data: fix}})
+	if len(actions) != 1 {
+		t.Fatalf("expected 1 code action, got %d", len(actions))
+	}
+	if actions[0].Title != fix.Title || actions[0].Edit.Changes["file:///README.md"][0].NewText != "ide" {
+		t.Errorf("unexpected code action: %+v", actions[0])
+	}
+}
@@ -7,7 +7,11 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"cdr.dev/slog"
 	"cdr.dev/slog/sloggers/sloghuman"
@@ -16,6 +20,33 @@ import (
 var logger = slog.Make(sloghuman.Sink(os.Stdout))
 
 func main() {
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		runCommand(os.Args[1], os.Args[2:])
+		return
+	}
+
+	flags := flag.NewFlagSet("readmevalidation", flag.ExitOnError)
+	flags.Usage = func() {
+		printUsage()
+		fmt.Fprintln(os.Stderr, "\nValidation flags:")
+		flags.PrintDefaults()
+	}
+	identityMode := flags.String("github-identity", string(githubIdentityModeOff), fmt.Sprintf("How to verify contributor GitHub usernames (%s)", joinGithubIdentityModes()))
+	identityCache := flags.String("github-identity-cache", defaultGithubIdentityCache, "Path to the on-disk cache of GitHub account lookups")
+	skillsMirrorDir := flags.String("skills-mirror-dir", "", "Directory of bare git clones (<owner>/<repo>.git) to verify skill sources against; skipped when empty")
+	duplicatesBase := flags.String("duplicates-base", "", "Git ref to compare against; modules and templates added since then are checked for duplicates of existing ones")
+	watch := flags.Bool("watch", false, "Keep running, and re-validate the modules, templates, namespaces, and icons that change")
+	watchInterval := flags.Duration("watch-interval", 500*time.Millisecond, "How often --watch checks for changed files")
+	_ = flags.Parse(os.Args[1:])
+
+	if *watch {
+		if err := runWatchMode(*watchInterval); err != nil {
+			logger.Error(context.Background(), "watch mode failed", "error", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	logger.Info(context.Background(), "starting README validation")
 
 	// If there are fundamental problems with how the repo is structured, we can't make any guarantees that any further
@@ -26,11 +57,46 @@ func main() {
 		os.Exit(1)
 	}
 
+	errs := validateRegistry(validationOptions{
+		identityMode:    githubIdentityMode(*identityMode),
+		identityCache:   *identityCache,
+		skillsMirrorDir: *skillsMirrorDir,
+		duplicatesBase:  *duplicatesBase,
+	})
+	if len(errs) == 0 {
+		logger.Info(context.Background(), "processed all READMEs in directory", "dir", rootRegistryPath)
+		os.Exit(0)
+	}
+	for _, err := range errs {
+		logger.Error(context.Background(), err.Error())
+	}
+	os.Exit(1)
+}
+
+// validationOptions configures the optional checks of a full registry validation.
+type validationOptions struct {
+	identityMode    githubIdentityMode
+	identityCache   string
+	skillsMirrorDir string
+	duplicatesBase  string
+}
+
+// validateRegistry runs every validation phase that follows the repo structure check, and returns the errors of all
+// phases that failed.
+func validateRegistry(opts validationOptions) []error {
 	var errs []error
+	err := validateFrontmatterSchemas()
+	if err != nil {
+		errs = append(errs, err)
+	}
 	err = validateAllContributorFiles()
 	if err != nil {
 		errs = append(errs, err)
 	}
+	err = validateAllRegistryAliases()
+	if err != nil {
+		errs = append(errs, err)
+	}
 	err = validateAllCoderModules()
 	if err != nil {
 		errs = append(errs, err)
@@ -39,17 +105,42 @@ func main() {
 	if err != nil {
 		errs = append(errs, err)
 	}
+	err = validateAllContributorStatusPolicies()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	err = validateAllTagVocabulary()
+	if err != nil {
+		errs = append(errs, err)
+	}
 	err = validateAllCoderSkills()
 	if err != nil {
 		errs = append(errs, err)
 	}
-
-	if len(errs) == 0 {
-		logger.Info(context.Background(), "processed all READMEs in directory", "dir", rootRegistryPath)
-		os.Exit(0)
+	err = validateAllSkillSourceMirrors(opts.skillsMirrorDir)
+	if err != nil {
+		errs = append(errs, err)
 	}
-	for _, err := range errs {
-		logger.Error(context.Background(), err.Error())
+	err = validateAllCoderResourceTerraform()
+	if err != nil {
+		errs = append(errs, err)
 	}
-	os.Exit(1)
+	err = validateAllSupportedOS()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	err = validateNewResourceDuplicates(opts.duplicatesBase)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	err = validateAllIcons()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	err = validateAllGithubIdentities(opts.identityMode, opts.identityCache)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
 }
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// mcpProtocolVersion is the newest MCP protocol version the server implements.
+	mcpProtocolVersion = "2025-06-18"
+
+	// maxMCPMessageBytes is the largest JSON-RPC message the server accepts on stdin.
+	maxMCPMessageBytes = 4 * 1024 * 1024
+
+	jsonrpcMethodNotFound = -32601
+	jsonrpcInvalidParams  = -32602
+	jsonrpcParseError     = -32700
+)
+
+// mcpSupportedProtocolVersions lists every protocol version the server can speak. Clients asking for any other version
+// are answered with mcpProtocolVersion, as the specification requires.
+var mcpSupportedProtocolVersions = []string{"2024-11-05", "2025-03-26", mcpProtocolVersion}
+
+type jsonrpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type jsonrpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type jsonrpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *jsonrpcError   `json:"error,omitempty"`
+}
+
+// mcpTool is a tool exposed by the MCP server. call returns the text content of a successful tool call; errors are
+// reported to the client as tool errors rather than protocol errors, so that agents can read and act on them.
+type mcpTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+	call        func(s *mcpServer, args json.RawMessage) (string, error)
+}
+
+// mcpResourceArgs are the arguments of every tool that operates on a single resource.
+type mcpResourceArgs struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// mcpSearchArgs are the arguments of the search tool.
+type mcpSearchArgs struct {
+	Query string `json:"query"`
+	Type  string `json:"type"`
+	Tag   string `json:"tag"`
+	OS    string `json:"os"`
+}
+
+// mcpSearchResult is a single resource returned by the search tool.
+type mcpSearchResult struct {
+	ID          string   `json:"id"`
+	Type        string   `json:"type"`
+	DisplayName string   `json:"display_name,omitempty"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	SupportedOS []string `json:"supported_os,omitempty"`
+	Verified    bool     `json:"verified"`
+}
+
+var mcpResourceTypeSchema = map[string]any{
+	"type":        "string",
+	"enum":        []string{"module", "template"},
+	"description": "Resource type. Defaults to module.",
+}
+
+var mcpResourceInputSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"id":   map[string]any{"type": "string", "description": "Resource ID in the form <namespace>/<name>, e.g. coder/code-server."},
+		"type": mcpResourceTypeSchema,
+	},
+	"required": []string{"id"},
+}
+
+var mcpTools = []mcpTool{
+	{
+		Name:        "search_resources",
+		Description: "Search the modules and templates in the registry by text, tag, and supported operating system.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"query": map[string]any{"type": "string", "description": "Search terms, matched against names, descriptions, tags, README headings, and variable names. Tolerates typos."},
+				"type":  mcpResourceTypeSchema,
+				"tag":   map[string]any{"type": "string", "description": "Only return resources with this tag."},
+				"os":    map[string]any{"type": "string", "enum": operatingSystems, "description": "Only return resources that support this operating system."},
+			},
+		},
+		call: (*mcpServer).searchResources,
+	},
+	{
+		Name:        "get_readme",
+		Description: "Get the full README of a module or template, including its frontmatter.",
+		InputSchema: mcpResourceInputSchema,
+		call:        (*mcpServer).getReadme,
+	},
+	{
+		Name:        "get_variables",
+		Description: "Get the Terraform input variables of a module or template as JSON, including types, defaults, and whether they are required.",
+		InputSchema: mcpResourceInputSchema,
+		call:        (*mcpServer).getVariables,
+	},
+	{
+		Name:        "get_usage",
+		Description: "Get a ready-to-paste Terraform module block that adds a module to a template.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"id": map[string]any{"type": "string", "description": "Module ID in the form <namespace>/<name>, e.g. coder/code-server."},
+			},
+			"required": []string{"id"},
+		},
+		call: (*mcpServer).getUsage,
+	},
+}
+
+// mcpServer serves the registry catalog over the MCP stdio transport: newline-delimited JSON-RPC messages.
+type mcpServer struct {
+	catalog []catalogResource
+	index   searchIndex
+}
+
+func newMCPServer(catalog []catalogResource) *mcpServer {
+	var documents []searchDocument
+	for _, r := range catalog {
+		documents = append(documents, catalogSearchDocument(r))
+	}
+	return &mcpServer{catalog: catalog, index: newSearchIndex(documents)}
+}
+
+// mcpResourceType converts the singular resource type used by tool arguments into a registry directory name.
+func mcpResourceType(t string) (string, error) {
+	switch t {
+	case "", "module":
+		return "modules", nil
+	case "template":
+		return "templates", nil
+	default:
+		return "", xerrors.Errorf("unknown resource type %q (expected \"module\" or \"template\")", t)
+	}
+}
+
+func (s *mcpServer) resource(rawArgs json.RawMessage) (catalogResource, error) {
+	args := mcpResourceArgs{}
+	if err := json.Unmarshal(rawArgs, &args); err != nil {
+		return catalogResource{}, xerrors.Errorf("invalid arguments: %v", err)
+	}
+	resourceType, err := mcpResourceType(args.Type)
+	if err != nil {
+		return catalogResource{}, err
+	}
+	return findCatalogResource(s.catalog, resourceType, args.ID)
+}
+
+func (s *mcpServer) searchResources(rawArgs json.RawMessage) (string, error) {
+	args := mcpSearchArgs{}
+	if err := json.Unmarshal(rawArgs, &args); err != nil {
+		return "", xerrors.Errorf("invalid arguments: %v", err)
+	}
+	filter := searchFilter{tag: args.Tag, os: args.OS}
+	if args.Type != "" {
+		if _, err := mcpResourceType(args.Type); err != nil {
+			return "", err
+		}
+		filter.kind = args.Type
+	}
+
+	results := []mcpSearchResult{}
+	for _, r := range s.index.search(args.Query, filter) {
+		doc := r.document
+		results = append(results, mcpSearchResult{
+			ID:          doc.id,
+			Type:        doc.kind,
+			DisplayName: doc.displayName,
+			Description: doc.description,
+			Tags:        doc.tags,
+			SupportedOS: doc.supportedOS,
+			Verified:    doc.verified,
+		})
+	}
+	return marshalMCPText(results)
+}
+
+func (s *mcpServer) getReadme(rawArgs json.RawMessage) (string, error) {
+	r, err := s.resource(rawArgs)
+	if err != nil {
+		return "", err
+	}
+	return r.rawReadme, nil
+}
+
+func (s *mcpServer) getVariables(rawArgs json.RawMessage) (string, error) {
+	r, err := s.resource(rawArgs)
+	if err != nil {
+		return "", err
+	}
+	variables := r.terraform.variables()
+	if variables == nil {
+		variables = []terraformVariable{}
+	}
+	return marshalMCPText(variables)
+}
+
+func (s *mcpServer) getUsage(rawArgs json.RawMessage) (string, error) {
+	r, err := s.resource(rawArgs)
+	if err != nil {
+		return "", err
+	}
+	return r.usageBlock()
+}
+
+func marshalMCPText(v any) (string, error) {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// handle processes a single request and returns its result, or a JSON-RPC error.
+func (s *mcpServer) handle(req jsonrpcRequest) (any, *jsonrpcError) {
+	switch req.Method {
+	case "initialize":
+		params := struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}{}
+		_ = json.Unmarshal(req.Params, &params)
+		version := mcpProtocolVersion
+		for _, v := range mcpSupportedProtocolVersions {
+			if v == params.ProtocolVersion {
+				version = v
+			}
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "coder-registry", "version": "0.1.0"},
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		return map[string]any{"tools": mcpTools}, nil
+	case "tools/call":
+		params := struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}{}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &jsonrpcError{Code: jsonrpcInvalidParams, Message: err.Error()}
+		}
+		if len(params.Arguments) == 0 {
+			params.Arguments = json.RawMessage("{}")
+		}
+		for _, tool := range mcpTools {
+			if tool.Name != params.Name {
+				continue
+			}
+			text, err := tool.call(s, params.Arguments)
+			if err != nil {
+				return map[string]any{
+					"content": []map[string]string{{"type": "text", "text": err.Error()}},
+					"isError": true,
+				}, nil
+			}
+			return map[string]any{
+				"content": []map[string]string{{"type": "text", "text": text}},
+			}, nil
+		}
+		return nil, &jsonrpcError{Code: jsonrpcInvalidParams, Message: "unknown tool " + params.Name}
+	default:
+		return nil, &jsonrpcError{Code: jsonrpcMethodNotFound, Message: "method not found: " + req.Method}
+	}
+}
+
+// serve reads requests from in until it is closed, writing a response for every request that has an ID.
+// Notifications, which have no ID, never get a response.
+func (s *mcpServer) serve(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMCPMessageBytes)
+	encoder := json.NewEncoder(out)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		req := jsonrpcRequest{}
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			if err := encoder.Encode(jsonrpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &jsonrpcError{Code: jsonrpcParseError, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(req.ID) == 0 {
+			continue
+		}
+
+		result, rpcErr := s.handle(req)
+		if err := encoder.Encode(jsonrpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr}); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// runMCPCommand serves the registry catalog as an MCP server over stdin and stdout. Logs go to stderr, since stdout
+// is reserved for protocol messages.
+func runMCPCommand(_ []string) error {
+	logger = slog.Make(sloghuman.Sink(os.Stderr))
+	catalog, err := loadCatalog()
+	if err != nil {
+		return err
+	}
+	server := newMCPServer(catalog)
+	logger.Info(context.Background(), "serving registry catalog over MCP stdio", "num_resources", len(catalog))
+	return server.serve(os.Stdin, os.Stdout)
+}
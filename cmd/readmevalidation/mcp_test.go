@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMCPServer(t *testing.T) {
+	t.Parallel()
+
+	server := newMCPServer([]catalogResource{newTestCatalogResource(t, "# Code Server\n")})
+	requests := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"search_resources","arguments":{"query":"browsr","tag":"ide","os":"linux"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"get_readme","arguments":{"id":"acme/missing"}}}`,
+		`{"jsonrpc":"2.0","id":"five","method":"resources/list"}`,
+		`not json`,
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := server.serve(strings.NewReader(requests), &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	type response struct {
+		ID     json.RawMessage `json:"id"`
+		Result struct {
+			ProtocolVersion string `json:"protocolVersion"`
+			Tools           []struct {
+				Name string `json:"name"`
+			} `json:"tools"`
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+			IsError bool `json:"isError"`
+		} `json:"result"`
+		Error *jsonrpcError `json:"error"`
+	}
+	var responses []response
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		r := response{}
+		if err := decoder.Decode(&r); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		responses = append(responses, r)
+	}
+
+	// The notification must not get a response.
+	if len(responses) != 6 {
+		t.Fatalf("Expected 6 responses, got %d", len(responses))
+	}
+	if got := responses[0].Result.ProtocolVersion; got != "2025-03-26" {
+		t.Errorf("Expected negotiated protocol version 2025-03-26, got %q", got)
+	}
+	if got := len(responses[1].Result.Tools); got != len(mcpTools) {
+		t.Errorf("Expected %d tools, got %d", len(mcpTools), got)
+	}
+	if content := responses[2].Result.Content; len(content) != 1 || !strings.Contains(content[0].Text, `"id": "acme/code-server"`) {
+		t.Errorf("Expected search to return acme/code-server, got: %+v", content)
+	}
+	if !responses[3].Result.IsError || !strings.Contains(responses[3].Result.Content[0].Text, `no module with ID "acme/missing"`) {
+		t.Errorf("Expected tool error for a missing module, got: %+v", responses[3].Result)
+	}
+	if responses[4].Error == nil || responses[4].Error.Code != jsonrpcMethodNotFound || string(responses[4].ID) != `"five"` {
+		t.Errorf("Expected method not found error for ID \"five\", got: %+v", responses[4])
+	}
+	if responses[5].Error == nil || responses[5].Error.Code != jsonrpcParseError {
+		t.Errorf("Expected parse error, got: %+v", responses[5])
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	goversion "github.com/hashicorp/go-version"
+	"golang.org/x/xerrors"
+)
+
+// moduleReleaseTagPrefix is the prefix of the git tags created by scripts/tag_release.sh. Each tag has the form
+// "release/<namespace>/<module>/v<version>".
+const moduleReleaseTagPrefix = "release/"
+
+// readmeModuleVersionRe matches the version argument inside the Terraform usage block of a module README, which is
+// the version that scripts/tag_release.sh tags on release.
+var readmeModuleVersionRe = regexp.MustCompile(`^\s*version\s*=\s*"([^"]+)"`)
+
+// moduleRelease is a single released version of a registry module.
+type moduleRelease struct {
+	version *goversion.Version
+	// tag is the git tag for the release. It is empty when the release was inferred from the working tree because
+	// the repo has no release tags available.
+	tag string
+	// unverified is set when a constraint could only be matched against an older release that cannot be enumerated
+	// without tags, so nothing is known about that release's contents.
+	unverified bool
+}
+
+// moduleReleaseIndex holds every known release of every module in the registry, keyed by "<namespace>/<module>".
+type moduleReleaseIndex struct {
+	releases map[string][]moduleRelease
+	// fromTags is false when no release tags could be found at all (e.g. in a shallow clone). In that case each
+	// module's current README version is treated as its only release.
+	fromTags bool
+}
+
+// readmeModuleVersion returns the version pinned in the Terraform usage block of a module README.
+func readmeModuleVersion(readmeText string) (string, bool) {
+	_, _, body, err := splitFrontmatter(readmeText)
+	if err != nil {
+		return "", false
+	}
+
+	isInsideTerraform := false
+	lineScanner := bufio.NewScanner(strings.NewReader(body))
+	for lineScanner.Scan() {
+		nextLine := lineScanner.Text()
+		if strings.HasPrefix(nextLine, "```") {
+			isInsideTerraform = !isInsideTerraform && strings.HasPrefix(nextLine, "```tf")
+			continue
+		}
+		if !isInsideTerraform {
+			continue
+		}
+		if match := readmeModuleVersionRe.FindStringSubmatch(nextLine); match != nil {
+			return match[1], true
+		}
+	}
+	return "", false
+}
+
+// listReleaseTags returns every module release tag in the repo. A missing git binary or a directory that is not a
+// git repo are treated the same as a repo without tags.
+func listReleaseTags() []string {
+	out, err := exec.Command("git", "tag", "--list", moduleReleaseTagPrefix+"*").Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(out))
+}
+
+// loadModuleReleaseIndex indexes the releases of every module. Releases tagged under an old path in the alias file
+// count as releases of the module at its new path.
+func loadModuleReleaseIndex(modules []coderResourceTerraform, aliases registryAliases) (moduleReleaseIndex, error) {
+	index := moduleReleaseIndex{releases: map[string][]moduleRelease{}}
+
+	for _, tag := range listReleaseTags() {
+		segments := strings.Split(strings.TrimPrefix(tag, moduleReleaseTagPrefix), "/")
+		if len(segments) != 3 || !strings.HasPrefix(segments[2], "v") {
+			continue
+		}
+		v, err := goversion.NewVersion(strings.TrimPrefix(segments[2], "v"))
+		if err != nil {
+			continue
+		}
+		key, _ := aliases.resolveModule(segments[0] + "/" + segments[1])
+		index.releases[key] = append(index.releases[key], moduleRelease{version: v, tag: tag})
+		index.fromTags = true
+	}
+	if index.fromTags {
+		return index, nil
+	}
+
+	logger.Warn(context.Background(), "no module release tags found; treating each module's README version as its only release")
+	var errs []error
+	for _, m := range modules {
+		readmePath := path.Join(m.dirPath, "README.md")
+		rm, err := os.ReadFile(readmePath)
+		if err != nil {
+			errs = append(errs, addFilePathToError(readmePath, err))
+			continue
+		}
+		raw, ok := readmeModuleVersion(string(rm))
+		if !ok {
+			continue
+		}
+		v, err := goversion.NewVersion(raw)
+		if err != nil {
+			errs = append(errs, addFilePathToError(readmePath, xerrors.Errorf("module version %q is not valid semver: %v", raw, err)))
+			continue
+		}
+		index.releases[moduleKey(m.dirPath)] = []moduleRelease{{version: v}}
+	}
+	if len(errs) != 0 {
+		return moduleReleaseIndex{}, validationPhaseError{
+			phase:  validationPhaseFile,
+			errors: errs,
+		}
+	}
+	return index, nil
+}
+
+// moduleKey returns the "<namespace>/<module>" key for a module directory under /registry.
+func moduleKey(dirPath string) string {
+	segments := strings.Split(path.Clean(dirPath), "/")
+	if len(segments) < 3 {
+		return dirPath
+	}
+	return segments[len(segments)-3] + "/" + segments[len(segments)-1]
+}
+
+// resolve returns the newest release of a module that satisfies a Terraform version constraint, mirroring how
+// Terraform itself picks a module version from the registry.
+func (idx moduleReleaseIndex) resolve(key string, constraint string) (moduleRelease, error) {
+	constraints, err := goversion.NewConstraint(constraint)
+	if err != nil {
+		return moduleRelease{}, xerrors.Errorf("version constraint %q is not valid: %v", constraint, err)
+	}
+
+	releases := idx.releases[key]
+	if len(releases) == 0 {
+		return moduleRelease{}, xerrors.Errorf("module %q has no releases", key)
+	}
+
+	if !idx.fromTags {
+		return resolveUntagged(releases[0], constraints, constraint)
+	}
+
+	var best *moduleRelease
+	for i, r := range releases {
+		if !constraints.Check(r.version) {
+			continue
+		}
+		if best == nil || r.version.GreaterThan(best.version) {
+			best = &releases[i]
+		}
+	}
+	if best == nil {
+		var known []string
+		for _, r := range releases {
+			known = append(known, r.version.String())
+		}
+		slices.Sort(known)
+		return moduleRelease{}, xerrors.Errorf("no release of module %q matches version %q (released: %s)", key, constraint, strings.Join(known, ", "))
+	}
+	return *best, nil
+}
+
+// resolveUntagged is the fallback for resolve when the repo has no release tags, so the only known release of a
+// module is its current version. Older releases cannot be enumerated, so a constraint is accepted as long as some
+// version no newer than the current one could satisfy it. Constraints that can only be met by a future release are
+// still rejected.
+func resolveUntagged(current moduleRelease, constraints goversion.Constraints, constraint string) (moduleRelease, error) {
+	var candidates []*goversion.Version
+	for _, c := range constraints {
+		// Each constraint stringifies as "<operator> <version>", and the version it names is the lowest version that
+		// the pessimistic, equality and lower-bound operators can accept.
+		fields := strings.Fields(c.String())
+		if v, err := goversion.NewVersion(fields[len(fields)-1]); err == nil {
+			candidates = append(candidates, v)
+		}
+	}
+
+	if constraints.Check(current.version) {
+		return current, nil
+	}
+	for _, v := range candidates {
+		if v.LessThan(current.version) && constraints.Check(v) {
+			return moduleRelease{version: v, unverified: true}, nil
+		}
+	}
+	return moduleRelease{}, xerrors.Errorf("version %q can only be satisfied by a release newer than the current version %s", constraint, current.version)
+}
+
+// releaseTreePath returns the directory a module lived in when a release tag was created, which differs from its
+// current directory when the module was moved since.
+func releaseTreePath(tag string) string {
+	segments := strings.Split(strings.TrimPrefix(tag, moduleReleaseTagPrefix), "/")
+	return path.Join(path.Clean(rootRegistryPath), segments[0], "modules", segments[1]) + "/"
+}
+
+// moduleVariablesAtRelease returns the names of all input variables declared by a module at a specific release. When
+// the release has no tag, the module's current working tree is used instead.
+func moduleVariablesAtRelease(dirPath string, release moduleRelease) ([]string, error) {
+	var files []terraformFile
+	if release.tag == "" {
+		tf, errs := parseCoderResourceTerraform("modules", dirPath)
+		if len(errs) != 0 {
+			return nil, errs[0]
+		}
+		files = tf.files
+	} else {
+		treePath := releaseTreePath(release.tag)
+		out, err := exec.Command("git", "ls-tree", "--name-only", release.tag, treePath).Output()
+		if err != nil {
+			return nil, xerrors.Errorf("failed to list files of %q at %q: %v", treePath, release.tag, err)
+		}
+		for _, filePath := range strings.Fields(string(out)) {
+			if !strings.HasSuffix(filePath, ".tf") {
+				continue
+			}
+			src, err := exec.Command("git", "show", release.tag+":"+filePath).Output()
+			if err != nil {
+				return nil, xerrors.Errorf("failed to read %q at %q: %v", filePath, release.tag, err)
+			}
+			tf, err := parseTerraformFile(filePath, src)
+			if err != nil {
+				return nil, addFilePathToError(filePath, err)
+			}
+			files = append(files, tf)
+		}
+	}
+
+	var variables []string
+	for _, f := range files {
+		for _, b := range f.body.Blocks {
+			if b.Type == "variable" && len(b.Labels) == 1 {
+				variables = append(variables, b.Labels[0])
+			}
+		}
+	}
+	return variables, nil
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+)
+
+// relativeURLRe matches the URLs of Markdown links and images, HTML src and href attributes, and the icon and avatar
+// frontmatter fields. The URL itself is the second group.
+var relativeURLRe = regexp.MustCompile(`(?m)(\]\(|\b(?:src|href)="|^(?:icon|avatar):[ \t]*"?)([^)"\s]+)`)
+
+// mvSkippedDirs are directories that never contain files that reference registry paths.
+var mvSkippedDirs = []string{".git", "node_modules", ".terraform"}
+
+// registryMove is a namespace rename, when both paths are a "<namespace>", or a module move, when both paths are a
+// "<namespace>/<module>".
+type registryMove struct {
+	oldPath string
+	newPath string
+}
+
+func (m registryMove) isNamespace() bool {
+	return !strings.Contains(m.oldPath, "/")
+}
+
+// dir returns the directory of a namespace or module path in the registry.
+func (m registryMove) dir(registryPath string) string {
+	namespace, moduleName, isModule := strings.Cut(registryPath, "/")
+	if !isModule {
+		return path.Join(rootRegistryPath, namespace)
+	}
+	return path.Join(rootRegistryPath, namespace, "modules", moduleName)
+}
+
+// newRegistryMove checks that a move is possible before anything is changed: the old path must exist, and the new
+// path must be a valid name that is neither in use nor the old name of something that was moved before.
+func newRegistryMove(oldPath string, newPath string, aliases registryAliases, namespaces []string, modules []string) (registryMove, error) {
+	m := registryMove{oldPath: strings.Trim(oldPath, "/"), newPath: strings.Trim(newPath, "/")}
+	if m.oldPath == m.newPath {
+		return m, xerrors.Errorf("%q is already at %q", m.oldPath, m.newPath)
+	}
+
+	if m.isNamespace() {
+		switch {
+		case strings.Contains(m.newPath, "/"):
+			return m, xerrors.Errorf("namespace %q can only be renamed to another namespace, not to %q", m.oldPath, m.newPath)
+		case !slices.Contains(namespaces, m.oldPath):
+			return m, xerrors.Errorf("namespace %q does not exist", m.oldPath)
+		case slices.Contains(namespaces, m.newPath):
+			return m, xerrors.Errorf("namespace %q already exists", m.newPath)
+		}
+		if err := validateNamespaceName(m.newPath); err != nil {
+			return m, xerrors.Errorf("invalid namespace %q: %w", m.newPath, err)
+		}
+		if target, ok := aliases.Namespaces[m.newPath]; ok {
+			return m, xerrors.Errorf("namespace %q was renamed to %q before, and old names cannot be reused", m.newPath, target)
+		}
+		return m, nil
+	}
+
+	newNamespace, newName, ok := strings.Cut(m.newPath, "/")
+	switch {
+	case !ok || strings.Contains(newName, "/"):
+		return m, xerrors.Errorf("module %q can only be moved to another \"<namespace>/<module>\" path, not to %q", m.oldPath, m.newPath)
+	case !slices.Contains(modules, m.oldPath):
+		return m, xerrors.Errorf("module %q does not exist", m.oldPath)
+	case slices.Contains(modules, m.newPath):
+		return m, xerrors.Errorf("module %q already exists", m.newPath)
+	case !slices.Contains(namespaces, newNamespace):
+		return m, xerrors.Errorf("namespace %q does not exist; add its contributor README before moving modules into it", newNamespace)
+	case !validNameRe.MatchString(newName):
+		return m, xerrors.Errorf("invalid module name %q (only alphanumeric characters and hyphens are allowed)", newName)
+	}
+	if target, aliased := aliases.resolveModule(m.newPath); aliased {
+		return m, xerrors.Errorf("module %q was moved to %q before, and old paths cannot be reused", m.newPath, target)
+	}
+	return m, nil
+}
+
+// rewriteModuleSources replaces every registry module source that uses the old path with the new path.
+func (m registryMove) rewriteModuleSources(text string) string {
+	prefix := regexp.QuoteMeta(registryModuleSourcePrefix)
+	if m.isNamespace() {
+		re := regexp.MustCompile(prefix + regexp.QuoteMeta(m.oldPath) + `/([a-zA-Z0-9-]+)/coder\b`)
+		return re.ReplaceAllString(text, registryModuleSourcePrefix+m.newPath+"/${1}/coder")
+	}
+	re := regexp.MustCompile(prefix + regexp.QuoteMeta(m.oldPath) + `/coder\b`)
+	return re.ReplaceAllLiteralString(text, registryModuleSourcePrefix+m.newPath+"/coder")
+}
+
+// relocateRelativeURL rewrites a relative URL in a file that moved from oldFileDir to newFileDir, so that it keeps
+// pointing at the same file. Targets inside the moved directory moved along with it. Absolute URLs, anchors, and URLs
+// that still resolve to the right file are returned unchanged.
+func (m registryMove) relocateRelativeURL(url string, oldFileDir string, newFileDir string) string {
+	if url == "" || strings.Contains(url, ":") || strings.HasPrefix(url, "/") || strings.HasPrefix(url, "#") {
+		return url
+	}
+	filePart, suffix := url, ""
+	if i := strings.IndexAny(url, "?#"); i != -1 {
+		filePart, suffix = url[:i], url[i:]
+	}
+
+	oldRoot, newRoot := m.dir(m.oldPath), m.dir(m.newPath)
+	target := path.Join(oldFileDir, filePart)
+	if rest, ok := strings.CutPrefix(target, oldRoot); ok && (rest == "" || strings.HasPrefix(rest, "/")) {
+		target = newRoot + rest
+	}
+	if path.Join(newFileDir, filePart) == target {
+		return url
+	}
+	relocated, err := filepath.Rel(newFileDir, target)
+	if err != nil {
+		return url
+	}
+	relocated = filepath.ToSlash(relocated)
+	if strings.HasPrefix(filePart, "./") && !strings.HasPrefix(relocated, "../") {
+		relocated = "./" + relocated
+	}
+	return relocated + suffix
+}
+
+// relocateRelativeURLs rewrites every relative URL in the text of a Markdown file that moved.
+func (m registryMove) relocateRelativeURLs(text string, oldFileDir string, newFileDir string) string {
+	var b strings.Builder
+	last := 0
+	for _, match := range relativeURLRe.FindAllStringSubmatchIndex(text, -1) {
+		start, end := match[4], match[5]
+		b.WriteString(text[last:start])
+		b.WriteString(m.relocateRelativeURL(text[start:end], oldFileDir, newFileDir))
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// rewriteFile applies a rewrite to the content of a file, and only writes the file back when it changed.
+func rewriteFile(filePath string, rewrite func(string) string) (bool, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return false, err
+	}
+	rewritten := rewrite(string(content))
+	if rewritten == string(content) {
+		return false, nil
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(filePath, []byte(rewritten), info.Mode().Perm())
+}
+
+// apply moves the directory and rewrites every reference to the old path in the repo. It returns the path of every
+// file it rewrote.
+func (m registryMove) apply() ([]string, error) {
+	oldDir, newDir := m.dir(m.oldPath), m.dir(m.newPath)
+	if err := os.MkdirAll(path.Dir(newDir), 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(oldDir, newDir); err != nil {
+		return nil, err
+	}
+	// Moving the last module out of a namespace leaves its modules directory empty. Removing a directory that still
+	// has entries fails, which is fine.
+	if !m.isNamespace() {
+		_ = os.Remove(path.Dir(oldDir))
+	}
+
+	var rewritten []string
+	err := filepath.WalkDir(newDir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() || path.Ext(filePath) != ".md" {
+			return err
+		}
+		newFileDir := path.Dir(filePath)
+		oldFileDir := oldDir + strings.TrimPrefix(newFileDir, newDir)
+		changed, err := rewriteFile(filePath, func(text string) string {
+			return m.relocateRelativeURLs(text, oldFileDir, newFileDir)
+		})
+		if changed {
+			rewritten = append(rewritten, filePath)
+		}
+		return err
+	})
+	if err != nil {
+		return rewritten, err
+	}
+
+	err = filepath.WalkDir(".", func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if slices.Contains(mvSkippedDirs, d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Symlinks point at files that are rewritten under their own path.
+		if ext := path.Ext(filePath); !d.Type().IsRegular() || (ext != ".md" && ext != ".tf") {
+			return nil
+		}
+		changed, err := rewriteFile(filePath, m.rewriteModuleSources)
+		if changed && !slices.Contains(rewritten, filePath) {
+			rewritten = append(rewritten, filePath)
+		}
+		return err
+	})
+	slices.Sort(rewritten)
+	return rewritten, err
+}
+
+// yamlMappingEntry returns the value of a key in a YAML mapping node, adding an empty mapping under the key when it
+// is missing.
+func yamlMappingEntry(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	value := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	return value
+}
+
+// recordAlias adds the old path of a move to the alias file, and points existing aliases that led to the old path at
+// the new one, so that redirects never chain. Comments in the file are kept.
+func (m registryMove) recordAlias() error {
+	content, err := os.ReadFile(registryAliasesPath)
+	if err != nil {
+		return err
+	}
+	doc := yaml.Node{}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return addFilePathToError(registryAliasesPath, err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return xerrors.Errorf("%q: expected a mapping with namespaces and modules", registryAliasesPath)
+	}
+
+	namespaces := yamlMappingEntry(doc.Content[0], "namespaces")
+	modules := yamlMappingEntry(doc.Content[0], "modules")
+	for _, mapping := range []*yaml.Node{namespaces, modules} {
+		for i := 1; i < len(mapping.Content); i += 2 {
+			target := mapping.Content[i]
+			switch {
+			case target.Value == m.oldPath:
+				target.Value = m.newPath
+			case m.isNamespace() && strings.HasPrefix(target.Value, m.oldPath+"/"):
+				target.Value = m.newPath + strings.TrimPrefix(target.Value, m.oldPath)
+			}
+		}
+	}
+
+	section := modules
+	if m.isNamespace() {
+		section = namespaces
+	}
+	section.Content = append(section.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: m.oldPath},
+		&yaml.Node{Kind: yaml.ScalarNode, Value: m.newPath},
+	)
+	// An empty mapping is written as "{}", so switch to block style now that it has entries.
+	section.Style = 0
+	type pair struct{ key, value *yaml.Node }
+	var pairs []pair
+	for i := 0; i+1 < len(section.Content); i += 2 {
+		pairs = append(pairs, pair{section.Content[i], section.Content[i+1]})
+	}
+	slices.SortStableFunc(pairs, func(a, b pair) int { return strings.Compare(a.key.Value, b.key.Value) })
+	section.Content = section.Content[:0]
+	for _, p := range pairs {
+		section.Content = append(section.Content, p.key, p.value)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(registryAliasesPath, buf.Bytes(), 0o644)
+}
+
+func runMvCommand(args []string) error {
+	if len(args) != 2 {
+		return xerrors.New("usage: readmevalidation mv <namespace> <new-namespace>, or mv <namespace>/<module> <namespace>/<module>")
+	}
+	aliases, err := loadRegistryAliases()
+	if err != nil {
+		return err
+	}
+	namespaces, modules, err := registryNamespacesAndModules()
+	if err != nil {
+		return err
+	}
+	m, err := newRegistryMove(args[0], args[1], aliases, namespaces, modules)
+	if err != nil {
+		return err
+	}
+
+	rewritten, err := m.apply()
+	if err != nil {
+		return xerrors.Errorf("failed to move %q to %q: %w", m.oldPath, m.newPath, err)
+	}
+	logger.Info(context.Background(), "moved registry path", "from", m.dir(m.oldPath), "to", m.dir(m.newPath), "num_rewritten_files", len(rewritten))
+	for _, filePath := range rewritten {
+		logger.Info(context.Background(), "rewrote references", "path", filePath)
+	}
+	if err := m.recordAlias(); err != nil {
+		return err
+	}
+	if err := writeRegistryRedirects(); err != nil {
+		return err
+	}
+	if err := writeCodeowners(); err != nil {
+		return err
+	}
+
+	if err := validateRepoStructure(); err != nil {
+		return err
+	}
+	errs := validateRegistry(validationOptions{identityMode: githubIdentityModeOff})
+	for _, err := range errs {
+		logger.Error(context.Background(), err.Error())
+	}
+	if len(errs) != 0 {
+		return xerrors.Errorf("moved %q to %q, but the registry no longer validates; fix the errors above before committing", m.oldPath, m.newPath)
+	}
+	return nil
+}
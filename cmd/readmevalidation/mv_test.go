@@ -0,0 +1,123 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNewRegistryMove(t *testing.T) {
+	t.Parallel()
+
+	aliases := registryAliases{
+		Namespaces: map[string]string{"AJ0070": "aj0070", "m4rrypro": "umair"},
+		Modules:    map[string]string{"coder/vscode": "coder/vscode-web"},
+	}
+	namespaces := []string{"aj0070", "coder", "umair"}
+	modules := []string{"aj0070/pgadmin", "coder/code-server", "coder/vscode-web"}
+
+	testCases := []struct {
+		name     string
+		oldPath  string
+		newPath  string
+		expected string
+	}{
+		{name: "rename namespace", oldPath: "umair", newPath: "umair-k"},
+		{name: "move module", oldPath: "coder/code-server/", newPath: "umair/code-server"},
+		{name: "same path", oldPath: "umair", newPath: "umair", expected: `"umair" is already at "umair"`},
+		{name: "missing namespace", oldPath: "acme", newPath: "acme-inc", expected: `namespace "acme" does not exist`},
+		{name: "existing namespace", oldPath: "umair", newPath: "coder", expected: `namespace "coder" already exists`},
+		{name: "mixed-case namespace", oldPath: "umair", newPath: "Umair", expected: "must be lowercase"},
+		{name: "reused namespace name", oldPath: "aj0070", newPath: "m4rrypro", expected: `namespace "m4rrypro" was renamed to "umair" before`},
+		{name: "namespace to module", oldPath: "umair", newPath: "coder/umair", expected: "can only be renamed to another namespace"},
+		{name: "missing module", oldPath: "coder/missing", newPath: "umair/missing", expected: `module "coder/missing" does not exist`},
+		{name: "existing module", oldPath: "coder/code-server", newPath: "coder/vscode-web", expected: `module "coder/vscode-web" already exists`},
+		{name: "missing target namespace", oldPath: "coder/code-server", newPath: "acme/code-server", expected: `namespace "acme" does not exist`},
+		{name: "reused module path", oldPath: "coder/code-server", newPath: "coder/vscode", expected: `module "coder/vscode" was moved to "coder/vscode-web" before`},
+		{name: "module into renamed namespace", oldPath: "coder/code-server", newPath: "AJ0070/code-server", expected: `namespace "AJ0070" does not exist`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newRegistryMove(tc.oldPath, tc.newPath, aliases, namespaces, modules)
+			if tc.expected == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected error containing %q, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestRegistryMoveRewrites(t *testing.T) {
+	t.Parallel()
+
+	text := `module "a" { source = "registry.coder.com/coder/code-server/coder" }
+module "b" { source = "registry.coder.com/coder/code-server-extra/coder" }
+module "c" { source = "registry.coder.com/coder-labs/code-server/coder" }
+# See https://registry.coder.com/modules/coder/code-server
+`
+	moduleMove := registryMove{oldPath: "coder/code-server", newPath: "umair/vscode-server"}
+	expected := strings.Replace(text, "coder/code-server/coder", "umair/vscode-server/coder", 1)
+	if got := moduleMove.rewriteModuleSources(text); got != expected {
+		t.Errorf("unexpected module move rewrite:\n%s", got)
+	}
+	namespaceMove := registryMove{oldPath: "coder", newPath: "coder-oss"}
+	expected = strings.Replace(strings.Replace(text, "com/coder/code-server/", "com/coder-oss/code-server/", 1), "com/coder/code-server-extra/", "com/coder-oss/code-server-extra/", 1)
+	if got := namespaceMove.rewriteModuleSources(text); got != expected {
+		t.Errorf("unexpected namespace rename rewrite:\n%s", got)
+	}
+
+	readme := `---
+icon: ../../../../.icons/code.svg
+---
+
+![Screenshot](../../.images/screenshot.png)
+<img src="./docs/diagram.svg#dark" /> [Docs](https://coder.com/docs) [Options](#options)
+`
+	relocated := moduleMove.relocateRelativeURLs(readme, "registry/coder/modules/code-server", "registry/umair/modules/vscode-server")
+	expected = strings.Replace(readme, "../../.images/screenshot.png", "../../../coder/.images/screenshot.png", 1)
+	if relocated != expected {
+		t.Errorf("unexpected relocated URLs:\n%s", relocated)
+	}
+	if got := namespaceMove.relocateRelativeURLs(readme, "registry/coder/modules/code-server", "registry/coder-oss/modules/code-server"); got != readme {
+		t.Errorf("expected a namespace rename to keep every relative URL, got:\n%s", got)
+	}
+}
+
+func TestRegistryMoveRecordAlias(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	writeTestFile(t, registryAliasesPath, `# Aliases.
+namespaces:
+  AJ0070: umair
+# Module aliases.
+modules: {}
+`)
+	if err := (registryMove{oldPath: "umair", newPath: "umair-k"}).recordAlias(); err != nil {
+		t.Fatal(err)
+	}
+	if err := (registryMove{oldPath: "umair-k/linode", newPath: "coder/linode"}).recordAlias(); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(registryAliasesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `# Aliases.
+namespaces:
+  AJ0070: umair-k
+  umair: umair-k
+# Module aliases.
+modules:
+  umair-k/linode: coder/linode
+`
+	if string(content) != expected {
+		t.Errorf("unexpected alias file:\n%s", content)
+	}
+}
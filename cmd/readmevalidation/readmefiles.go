@@ -2,9 +2,7 @@ package main
 
 import (
 	"bufio"
-	"fmt"
 	"regexp"
-	"slices"
 	"strings"
 
 	"golang.org/x/xerrors"
@@ -16,6 +14,7 @@ type validationPhase string
 
 const (
 	rootRegistryPath = "./registry"
+	rootIconsPath    = "./.icons"
 
 	// --- validationPhases ---
 	// validationPhaseStructure indicates when the entire Registry
@@ -35,6 +34,22 @@ const (
 	// is having all its relative URLs be validated for whether they point to
 	// valid resources.
 	validationPhaseCrossReference validationPhase = "Cross-referencing relative asset URLs"
+
+	// validationPhaseTerraform indicates when the Terraform files of modules
+	// and templates are being parsed and validated.
+	validationPhaseTerraform validationPhase = "Terraform validation"
+
+	// validationPhaseIcons indicates when the assets in the top-level .icons
+	// directory are being decoded and validated.
+	validationPhaseIcons validationPhase = "Icon asset validation"
+
+	// validationPhaseIdentity indicates when the GitHub usernames in
+	// contributor profiles are being checked against real GitHub accounts.
+	validationPhaseIdentity validationPhase = "GitHub identity verification"
+
+	// validationPhaseSkillSources indicates when the skill sources declared
+	// by skills READMEs are being resolved against local git mirrors.
+	validationPhaseSkillSources validationPhase = "Skill source verification"
 	// --- end of validationPhases ---.
 )
 
@@ -52,51 +67,6 @@ type readme struct {
 	rawText  string
 }
 
-// separateFrontmatter attempts to separate a README file's frontmatter content from the main README body, returning
-// both values in that order. It does not validate whether the structure of the frontmatter is valid (i.e., that it's
-// structured as YAML).
-func separateFrontmatter(readmeText string) (readmeFrontmatter string, readmeBody string, err error) {
-	if readmeText == "" {
-		return "", "", xerrors.New("README is empty")
-	}
-
-	const fence = "---"
-
-	var fm strings.Builder
-	var body strings.Builder
-	fenceCount := 0
-
-	lineScanner := bufio.NewScanner(strings.NewReader(strings.TrimSpace(readmeText)))
-	for lineScanner.Scan() {
-		nextLine := lineScanner.Text()
-		if fenceCount < 2 && nextLine == fence {
-			fenceCount++
-			continue
-		}
-		// Break early if the very first line wasn't a fence, because then we know for certain that the README has problems.
-		if fenceCount == 0 {
-			break
-		}
-
-		// It should be safe to trim each line of the frontmatter on a per-line basis, because there shouldn't be any
-		// extra meaning attached to the indentation. The same does NOT apply to the README; best we can do is gather
-		// all the lines and then trim around it.
-		if inReadmeBody := fenceCount >= 2; inReadmeBody {
-			fmt.Fprintf(&body, "%s\n", nextLine)
-		} else {
-			fmt.Fprintf(&fm, "%s\n", strings.TrimSpace(nextLine))
-		}
-	}
-	if fenceCount < 2 {
-		return "", "", xerrors.New("README does not have two sets of frontmatter fences")
-	}
-	if fm.Len() == 0 {
-		return "", "", xerrors.New("readme has frontmatter fences but no frontmatter content")
-	}
-
-	return fm.String(), strings.TrimSpace(body.String()), nil
-}
-
 // TODO: This seems to work okay for now, but the really proper way of doing this is by parsing this as an AST, and then
 // checking the resulting nodes.
 func validateReadmeBody(body string) []error {
@@ -171,25 +141,3 @@ func validateReadmeBody(body string) []error {
 
 	return errs
 }
-
-func validateFrontmatterYamlKeys(frontmatter string, allowedKeys []string) []error {
-	if len(allowedKeys) == 0 {
-		return []error{xerrors.New("Set of allowed keys is empty")}
-	}
-
-	var key string
-	var cutOk bool
-	var line string
-
-	var errs []error
-	lineScanner := bufio.NewScanner(strings.NewReader(frontmatter))
-	for lineScanner.Scan() {
-		line = lineScanner.Text()
-		key, _, cutOk = strings.Cut(line, ":")
-		if !cutOk || slices.Contains(allowedKeys, key) {
-			continue
-		}
-		errs = append(errs, xerrors.Errorf("detected unknown key %q", key))
-	}
-	return errs
-}
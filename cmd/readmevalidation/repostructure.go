@@ -22,19 +22,8 @@ var validNameRe = regexp.MustCompile(`^[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?$
 // easy to mistype.
 var validNamespaceRe = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]*[a-z0-9])?$`)
 
-// grandfatheredMixedCaseNamespaces lists the namespaces that existed before the lowercase rule and cannot be renamed
-// yet. Both have published modules whose source paths are case-sensitive, and the registry server has no alias
-// mechanism for the old path. They are exempt until that migration happens. Do not add new entries.
-var grandfatheredMixedCaseNamespaces = []string{
-	"AJ0070",
-	"BenraouaneSoufiane",
-}
-
 // validateNamespaceName validates the directory name of a single namespace under /registry.
 func validateNamespaceName(namespaceName string) error {
-	if slices.Contains(grandfatheredMixedCaseNamespaces, namespaceName) {
-		return nil
-	}
 	if validNamespaceRe.MatchString(namespaceName) {
 		return nil
 	}
@@ -171,7 +160,7 @@ func validateRepoStructure() error {
 		errs = append(errs, vrdErrs...)
 	}
 
-	if _, err := os.Stat("./.icons"); err != nil {
+	if _, err := os.Stat(rootIconsPath); err != nil {
 		errs = append(errs, xerrors.New("missing top-level .icons directory (used for storing reusable Coder resource icons)"))
 	}
 
@@ -17,11 +17,9 @@ func TestValidateNamespaceName(t *testing.T) {
 		{name: "excellencedev", shouldPass: true},
 		{name: "iamtaochen", shouldPass: true},
 
-		// Mixed-case namespaces that predate the rule stay allowed.
-		{name: "AJ0070", shouldPass: true},
-		{name: "BenraouaneSoufiane", shouldPass: true},
-
-		// New mixed-case namespaces are rejected.
+		// Mixed-case namespaces are rejected.
+		{name: "AJ0070", shouldPass: false},
+		{name: "BenraouaneSoufiane", shouldPass: false},
 		{name: "Coder", shouldPass: false},
 		{name: "CoderLabs", shouldPass: false},
 		{name: "coder-Labs", shouldPass: false},
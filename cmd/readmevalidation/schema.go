@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// frontmatterSchemasDir is where the generated JSON Schemas for README frontmatter are written.
+	frontmatterSchemasDir = "./.github/schemas"
+
+	jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+)
+
+// frontmatterSchema describes the frontmatter of one kind of README, and how to generate its JSON Schema.
+type frontmatterSchema struct {
+	kind        string
+	title       string
+	frontmatter reflect.Type
+	// refine adds constraints that can't be expressed with struct tags, like enums taken from the validation code.
+	refine func(schema map[string]any)
+}
+
+var frontmatterSchemas = []frontmatterSchema{
+	{
+		kind:        "resource",
+		title:       "Coder Registry module or template README frontmatter",
+		frontmatter: reflect.TypeFor[coderResourceFrontmatter](),
+		refine: func(schema map[string]any) {
+			props := schemaProperties(schema)
+			props["supported_os"].(map[string]any)["items"].(map[string]any)["enum"] = operatingSystems
+			for _, key := range deprecatedCoderResourceStructKeys {
+				props[key] = map[string]any{"deprecated": true, "description": "Deprecated and ignored."}
+			}
+		},
+	},
+	{
+		kind:        "contributor",
+		title:       "Coder Registry contributor README frontmatter",
+		frontmatter: reflect.TypeFor[contributorProfileFrontmatter](),
+		refine: func(schema map[string]any) {
+			props := schemaProperties(schema)
+			props["status"].(map[string]any)["enum"] = validContributorStatuses
+			props["github"].(map[string]any)["maxLength"] = maxGithubLoginLength
+		},
+	},
+	{
+		kind:        "skills",
+		title:       "Coder Registry skills README frontmatter",
+		frontmatter: reflect.TypeFor[coderSkillsFrontmatter](),
+		refine: func(schema map[string]any) {
+			sources := schemaProperties(schema)["sources"].(map[string]any)
+			sources["minItems"] = 1
+			source := sources["items"].(map[string]any)
+			schemaProperties(source)["repo"].(map[string]any)["pattern"] = skillsRepoSpecRe.String()
+			schemaProperties(source)["skills"].(map[string]any)["propertyNames"] = map[string]any{
+				"pattern":   skillNameRe.String(),
+				"maxLength": maxSkillNameLength,
+			}
+		},
+	},
+}
+
+// frontmatterKeys returns the YAML keys of a frontmatter struct, in field order.
+func frontmatterKeys(t reflect.Type) []string {
+	var keys []string
+	for _, field := range reflect.VisibleFields(t) {
+		if key := yamlFieldName(field); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// yamlFieldName returns the key a struct field is decoded from, or an empty string for fields YAML ignores.
+func yamlFieldName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+func schemaProperties(schema map[string]any) map[string]any {
+	return schema["properties"].(map[string]any)
+}
+
+// typeSchema returns the JSON Schema of a Go type, following the same rules yaml.v3 uses to decode into it. Struct
+// fields are described by their jsonschema_description tag, and marked as required with a `jsonschema:"required"` tag.
+func typeSchema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]any{}
+		required := []string{}
+		for _, field := range reflect.VisibleFields(t) {
+			key := yamlFieldName(field)
+			if key == "" {
+				continue
+			}
+			prop := typeSchema(field.Type)
+			if desc := field.Tag.Get("jsonschema_description"); desc != "" {
+				prop["description"] = desc
+			}
+			properties[key] = prop
+			if slices.Contains(strings.Split(field.Tag.Get("jsonschema"), ","), "required") {
+				required = append(required, key)
+			}
+		}
+		schema := map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+		if len(required) != 0 {
+			schema["required"] = required
+		}
+		return schema
+	default:
+		panic("unsupported frontmatter field type " + t.String())
+	}
+}
+
+// generate returns the JSON Schema document for the frontmatter.
+func (s frontmatterSchema) generate() map[string]any {
+	schema := typeSchema(s.frontmatter)
+	s.refine(schema)
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = s.title
+	return schema
+}
+
+func (s frontmatterSchema) filePath() string {
+	return path.Join(frontmatterSchemasDir, s.kind+".schema.json")
+}
+
+func (s frontmatterSchema) render() ([]byte, error) {
+	content, err := json.MarshalIndent(s.generate(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+// validateFrontmatterSchemas validates that the committed JSON Schemas match the ones generated from the frontmatter
+// structs.
+func validateFrontmatterSchemas() error {
+	var errs []error
+	for _, s := range frontmatterSchemas {
+		generated, err := s.render()
+		if err != nil {
+			return err
+		}
+		committed, err := os.ReadFile(s.filePath())
+		if err != nil {
+			errs = append(errs, addFilePathToError(s.filePath(), err))
+			continue
+		}
+		if string(committed) != string(generated) {
+			errs = append(errs, xerrors.Errorf("%q: file is out of date with the frontmatter types; regenerate it with \"./readmevalidation schema\"", s.filePath()))
+		}
+	}
+	if len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseCrossReference,
+			errors: errs,
+		}
+	}
+	return nil
+}
+
+// runSchemaCommand writes the JSON Schema of every kind of README frontmatter, or prints the schema of a single kind
+// when one is named.
+func runSchemaCommand(args []string) error {
+	if len(args) > 1 {
+		return xerrors.New("expected at most one schema kind")
+	}
+	if len(args) == 1 {
+		idx := slices.IndexFunc(frontmatterSchemas, func(s frontmatterSchema) bool { return s.kind == args[0] })
+		if idx == -1 {
+			var kinds []string
+			for _, s := range frontmatterSchemas {
+				kinds = append(kinds, s.kind)
+			}
+			return xerrors.Errorf("unknown schema kind %q (expected one of: %s)", args[0], strings.Join(kinds, ", "))
+		}
+		content, err := frontmatterSchemas[idx].render()
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(content)
+		return err
+	}
+
+	if err := os.MkdirAll(frontmatterSchemasDir, 0o755); err != nil {
+		return err
+	}
+	for _, s := range frontmatterSchemas {
+		content, err := s.render()
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(s.filePath(), content, 0o644); err != nil {
+			return err
+		}
+		logger.Info(context.Background(), "generated frontmatter JSON Schema", "path", s.filePath())
+	}
+	return nil
+}
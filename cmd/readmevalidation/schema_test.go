@@ -0,0 +1,89 @@
+package main
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestFrontmatterKeys(t *testing.T) {
+	t.Parallel()
+
+	type sample struct {
+		Name     string `yaml:"name"`
+		Options  string `yaml:"options,omitempty"`
+		Ignored  string `yaml:"-"`
+		Untagged string
+		Labels   map[string]string `yaml:"labels"`
+	}
+
+	testCases := []struct {
+		name     string
+		typ      reflect.Type
+		expected []string
+	}{
+		{
+			name:     "tags and defaults",
+			typ:      reflect.TypeFor[sample](),
+			expected: []string{"name", "options", "untagged", "labels"},
+		},
+		{
+			name:     "resource frontmatter",
+			typ:      reflect.TypeFor[coderResourceFrontmatter](),
+			expected: []string{"display_name", "description", "icon", "verified", "tags", "supported_os"},
+		},
+		{
+			name:     "contributor frontmatter",
+			typ:      reflect.TypeFor[contributorProfileFrontmatter](),
+			expected: []string{"display_name", "bio", "avatar", "github", "linkedin", "website", "support_email", "status"},
+		},
+		{
+			name:     "skills frontmatter",
+			typ:      reflect.TypeFor[coderSkillsFrontmatter](),
+			expected: []string{"icon", "sources"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := frontmatterKeys(tc.typ); !slices.Equal(got, tc.expected) {
+				t.Errorf("expected keys %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestFrontmatterSchemas(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range frontmatterSchemas {
+		t.Run(s.kind, func(t *testing.T) {
+			t.Parallel()
+
+			schema := s.generate()
+			if schema["additionalProperties"] != false {
+				t.Error("expected unknown top-level keys to be rejected")
+			}
+			var keys []string
+			for key := range schemaProperties(schema) {
+				keys = append(keys, key)
+			}
+			expected := frontmatterKeys(s.frontmatter)
+			if s.kind == "resource" {
+				expected = append(expected, deprecatedCoderResourceStructKeys...)
+			}
+			slices.Sort(keys)
+			slices.Sort(expected)
+			if !slices.Equal(keys, expected) {
+				t.Errorf("expected schema properties %v, got %v", expected, keys)
+			}
+			for _, key := range schema["required"].([]string) {
+				if !slices.Contains(keys, key) {
+					t.Errorf("required key %q is not a property", key)
+				}
+			}
+		})
+	}
+}
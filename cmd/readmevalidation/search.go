@@ -0,0 +1,340 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/agext/levenshtein"
+	"golang.org/x/xerrors"
+)
+
+// searchField is a part of a document that is indexed for search. Matches in some fields are worth more than others.
+type searchField string
+
+const (
+	searchFieldName        searchField = "name"
+	searchFieldDisplayName searchField = "display_name"
+	searchFieldDescription searchField = "description"
+	searchFieldTags        searchField = "tags"
+	searchFieldOS          searchField = "supported_os"
+	searchFieldHeadings    searchField = "headings"
+	searchFieldVariables   searchField = "variables"
+)
+
+// searchFieldBoosts weighs a term match by the field it was found in.
+var searchFieldBoosts = map[searchField]float64{
+	searchFieldName:        3,
+	searchFieldDisplayName: 4,
+	searchFieldTags:        3,
+	searchFieldDescription: 2,
+	searchFieldHeadings:    1.5,
+	searchFieldOS:          1,
+	searchFieldVariables:   1,
+}
+
+const (
+	// searchPrefixMatchWeight and searchFuzzyMatchWeight scale the score of terms that only match a query term by
+	// prefix or within the allowed edit distance, so that exact matches always rank first.
+	searchPrefixMatchWeight = 0.7
+	searchFuzzyMatchWeight  = 0.5
+
+	// minSearchPrefixLength is the shortest query term that is matched against term prefixes.
+	minSearchPrefixLength = 3
+)
+
+// searchDocument is a single module, template, or skill in the search index.
+type searchDocument struct {
+	kind        string
+	id          string
+	displayName string
+	description string
+	tags        []string
+	supportedOS []string
+	verified    bool
+	fields      map[searchField][]string
+}
+
+// searchIndex is an in-memory inverted index from terms to the documents that contain them.
+type searchIndex struct {
+	documents []searchDocument
+	// postings maps each term to the boosted score it contributes to every document that contains it.
+	postings map[string]map[int]float64
+}
+
+// searchResult is a document matching a query, with its relevance score.
+type searchResult struct {
+	document searchDocument
+	score    float64
+}
+
+// searchFilter narrows down search results. Empty fields match everything.
+type searchFilter struct {
+	kind     string
+	os       string
+	tag      string
+	verified bool
+}
+
+// tokenizeSearchText splits text into lowercase alphanumeric terms.
+func tokenizeSearchText(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// readmeHeadings returns the text of every Markdown heading in a README body, skipping code blocks.
+func readmeHeadings(body string) []string {
+	var headings []string
+	isInCodeBlock := false
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "```") {
+			isInCodeBlock = !isInCodeBlock
+			continue
+		}
+		if !isInCodeBlock && strings.HasPrefix(line, "#") {
+			headings = append(headings, strings.TrimLeft(line, "# "))
+		}
+	}
+	return headings
+}
+
+// catalogSearchDocument converts a module or template into a search document.
+func catalogSearchDocument(r catalogResource) searchDocument {
+	fm := r.readme.frontmatter
+	doc := searchDocument{
+		kind:        strings.TrimSuffix(r.resourceType, "s"),
+		id:          r.id(),
+		description: fm.Description,
+		tags:        fm.Tags,
+		supportedOS: fm.OperatingSystems,
+		verified:    fm.Verified != nil && *fm.Verified,
+		fields: map[searchField][]string{
+			searchFieldName:        {r.name},
+			searchFieldDescription: {fm.Description},
+			searchFieldTags:        fm.Tags,
+			searchFieldOS:          fm.OperatingSystems,
+			searchFieldHeadings:    readmeHeadings(r.readme.body),
+		},
+	}
+	if fm.DisplayName != nil {
+		doc.displayName = *fm.DisplayName
+		doc.fields[searchFieldDisplayName] = []string{*fm.DisplayName}
+	}
+	for _, v := range r.terraform.variables() {
+		doc.fields[searchFieldVariables] = append(doc.fields[searchFieldVariables], v.Name)
+	}
+	return doc
+}
+
+// skillSearchDocument converts a skill from the discovery index into a search document.
+func skillSearchDocument(entry skillsIndexEntry) searchDocument {
+	return searchDocument{
+		kind:        "skill",
+		id:          entry.Namespace + "/" + entry.Name,
+		displayName: entry.DisplayName,
+		description: entry.Description,
+		tags:        entry.Tags,
+		fields: map[searchField][]string{
+			searchFieldName:        {entry.Name},
+			searchFieldDisplayName: {entry.DisplayName},
+			searchFieldDescription: {entry.Description},
+			searchFieldTags:        entry.Tags,
+		},
+	}
+}
+
+func newSearchIndex(documents []searchDocument) searchIndex {
+	idx := searchIndex{
+		documents: documents,
+		postings:  map[string]map[int]float64{},
+	}
+	for docID, doc := range documents {
+		for field, values := range doc.fields {
+			for _, value := range values {
+				for _, term := range tokenizeSearchText(value) {
+					if idx.postings[term] == nil {
+						idx.postings[term] = map[int]float64{}
+					}
+					idx.postings[term][docID] += searchFieldBoosts[field]
+				}
+			}
+		}
+	}
+	return idx
+}
+
+// maxSearchEditDistance returns how many typos are tolerated in a query term of the given length.
+func maxSearchEditDistance(term string) int {
+	switch n := len(term); {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// termScores returns the score of every document for a single query term. Each document is scored by the best way
+// it matches the term: exactly, by prefix, or within the allowed edit distance.
+func (idx searchIndex) termScores(queryTerm string) map[int]float64 {
+	scores := map[int]float64{}
+	maxDistance := maxSearchEditDistance(queryTerm)
+	for term, postings := range idx.postings {
+		weight := 0.0
+		switch {
+		case term == queryTerm:
+			weight = 1
+		case len(queryTerm) >= minSearchPrefixLength && strings.HasPrefix(term, queryTerm):
+			weight = searchPrefixMatchWeight
+		case maxDistance > 0 && levenshtein.Distance(term, queryTerm, nil) <= maxDistance:
+			weight = searchFuzzyMatchWeight
+		default:
+			continue
+		}
+		for docID, score := range postings {
+			scores[docID] = max(scores[docID], score*weight)
+		}
+	}
+	return scores
+}
+
+func (f searchFilter) matches(doc searchDocument) bool {
+	if f.kind != "" && f.kind != doc.kind {
+		return false
+	}
+	if f.os != "" && !slices.Contains(doc.supportedOS, strings.ToLower(f.os)) {
+		return false
+	}
+	if f.tag != "" && !slices.ContainsFunc(doc.tags, func(tag string) bool { return strings.EqualFold(tag, f.tag) }) {
+		return false
+	}
+	return !f.verified || doc.verified
+}
+
+// search returns every document that matches all terms of the query and the filter, best match first. An empty query
+// returns every document that matches the filter, sorted by type and ID.
+func (idx searchIndex) search(query string, filter searchFilter) []searchResult {
+	terms := tokenizeSearchText(query)
+	var scores map[int]float64
+	for _, term := range terms {
+		termScores := idx.termScores(term)
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for docID := range scores {
+			if s, ok := termScores[docID]; ok {
+				scores[docID] += s
+			} else {
+				delete(scores, docID)
+			}
+		}
+	}
+
+	var results []searchResult
+	for docID, doc := range idx.documents {
+		if !filter.matches(doc) {
+			continue
+		}
+		if len(terms) == 0 {
+			results = append(results, searchResult{document: doc})
+			continue
+		}
+		if score, ok := scores[docID]; ok {
+			results = append(results, searchResult{document: doc, score: score})
+		}
+	}
+	slices.SortStableFunc(results, func(a, b searchResult) int {
+		if a.score != b.score {
+			if a.score > b.score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.document.kind+"/"+a.document.id, b.document.kind+"/"+b.document.id)
+	})
+	return results
+}
+
+// loadSearchIndex indexes every module, template, and skill in the registry.
+func loadSearchIndex() (searchIndex, error) {
+	catalog, err := loadCatalog()
+	if err != nil {
+		return searchIndex{}, err
+	}
+	allSkillsReadmes, err := aggregateSkillsReadmeFiles()
+	if err != nil {
+		return searchIndex{}, err
+	}
+	skillsReadmes, err := parseCoderSkillsReadmeFiles(allSkillsReadmes)
+	if err != nil {
+		return searchIndex{}, err
+	}
+
+	var documents []searchDocument
+	for _, r := range catalog {
+		documents = append(documents, catalogSearchDocument(r))
+	}
+	for _, entry := range buildSkillsIndex(skillsReadmes).Skills {
+		documents = append(documents, skillSearchDocument(entry))
+	}
+	return newSearchIndex(documents), nil
+}
+
+func printSearchResults(out io.Writer, results []searchResult) {
+	for _, r := range results {
+		name := r.document.displayName
+		if name == "" {
+			name = r.document.id
+		}
+		fmt.Fprintf(out, "%-9s %-40s %s\n", r.document.kind, r.document.id, name)
+		if r.document.description != "" {
+			fmt.Fprintf(out, "%-9s %s\n", "", r.document.description)
+		}
+	}
+}
+
+func runSearchCommand(args []string) error {
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	kind := flags.String("type", "", "Only return results of this type (module, template, or skill)")
+	osName := flags.String("os", "", "Only return results that support this operating system")
+	tag := flags.String("tag", "", "Only return results with this tag")
+	verified := flags.Bool("verified", false, "Only return verified results")
+	limit := flags.Int("limit", 10, "Maximum number of results to print (0 for no limit)")
+	// Allow flags before, after, and between query terms.
+	var queryTerms []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		queryTerms = append(queryTerms, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+	if *kind != "" && !slices.Contains([]string{"module", "template", "skill"}, *kind) {
+		return xerrors.Errorf("unknown type %q (expected module, template, or skill)", *kind)
+	}
+
+	idx, err := loadSearchIndex()
+	if err != nil {
+		return err
+	}
+	results := idx.search(strings.Join(queryTerms, " "), searchFilter{kind: *kind, os: *osName, tag: *tag, verified: *verified})
+	if *limit > 0 && len(results) > *limit {
+		results = results[:*limit]
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(os.Stderr, "No results.")
+		return nil
+	}
+	printSearchResults(os.Stdout, results)
+	return nil
+}
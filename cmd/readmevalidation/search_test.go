@@ -0,0 +1,128 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func newTestSearchIndex() searchIndex {
+	return newSearchIndex([]searchDocument{
+		{
+			kind:        "module",
+			id:          "coder/code-server",
+			displayName: "code-server",
+			supportedOS: []string{"linux", "macos"},
+			verified:    true,
+			fields: map[searchField][]string{
+				searchFieldName:        {"code-server"},
+				searchFieldDisplayName: {"code-server"},
+				searchFieldDescription: {"VS Code in the browser"},
+				searchFieldTags:        {"ide", "web"},
+				searchFieldVariables:   {"agent_id", "extensions"},
+			},
+		},
+		{
+			kind:        "module",
+			id:          "jane/vscode-web",
+			displayName: "VS Code Web",
+			supportedOS: []string{"linux", "windows"},
+			fields: map[searchField][]string{
+				searchFieldName:        {"vscode-web"},
+				searchFieldDisplayName: {"VS Code Web"},
+				searchFieldDescription: {"Run the official VS Code Web"},
+				searchFieldTags:        {"ide", "web"},
+				searchFieldHeadings:    {"Installing extensions"},
+			},
+		},
+		{
+			kind:        "template",
+			id:          "coder/docker",
+			displayName: "Docker Containers",
+			verified:    true,
+			fields: map[searchField][]string{
+				searchFieldName:        {"docker"},
+				searchFieldDisplayName: {"Docker Containers"},
+				searchFieldDescription: {"Provision Docker containers as Coder workspaces"},
+				searchFieldTags:        {"docker", "container"},
+			},
+		},
+		{
+			kind:        "skill",
+			id:          "coder/setup",
+			displayName: "Coder Setup",
+			fields: map[searchField][]string{
+				searchFieldName:        {"setup"},
+				searchFieldDisplayName: {"Coder Setup"},
+				searchFieldDescription: {"Install a new Coder deployment with Docker"},
+			},
+		},
+	})
+}
+
+func TestSearchIndex(t *testing.T) {
+	t.Parallel()
+
+	idx := newTestSearchIndex()
+	testCases := []struct {
+		name     string
+		query    string
+		filter   searchFilter
+		expected []string
+	}{
+		{
+			name:     "display name outranks description",
+			query:    "docker",
+			expected: []string{"coder/docker", "coder/setup"},
+		},
+		{
+			name:     "every term must match",
+			query:    "vs code browser",
+			expected: []string{"coder/code-server"},
+		},
+		{
+			name:     "typos are tolerated",
+			query:    "dockr contaners",
+			expected: []string{"coder/docker"},
+		},
+		{
+			name:     "prefixes match, weighed by field",
+			query:    "exten",
+			expected: []string{"jane/vscode-web", "coder/code-server"},
+		},
+		{
+			name:     "filter by type",
+			query:    "docker",
+			filter:   searchFilter{kind: "skill"},
+			expected: []string{"coder/setup"},
+		},
+		{
+			name:     "filter by OS",
+			query:    "ide",
+			filter:   searchFilter{os: "windows"},
+			expected: []string{"jane/vscode-web"},
+		},
+		{
+			name:     "filter by verified without a query",
+			filter:   searchFilter{verified: true},
+			expected: []string{"coder/code-server", "coder/docker"},
+		},
+		{
+			name:  "no match",
+			query: "kubernetes",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+			for _, r := range idx.search(tc.query, tc.filter) {
+				got = append(got, r.document.id)
+			}
+			if !slices.Equal(got, tc.expected) {
+				t.Errorf("search(%q) = %v, expected %v", tc.query, got, tc.expected)
+			}
+		})
+	}
+}
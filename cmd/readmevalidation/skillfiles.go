@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/xerrors"
+)
+
+// Limits from the agent skills specification (https://agentskills.io/specification).
+const (
+	maxSkillNameLength          = 64
+	maxSkillDescriptionLength   = 1024
+	maxSkillCompatibilityLength = 500
+)
+
+var (
+	// skillNameRe matches skill names: lowercase alphanumerics and single hyphens, not starting or ending with a
+	// hyphen.
+	skillNameRe = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+	// skillResourceLinkRe matches the targets of Markdown links and images in a SKILL.md body.
+	skillResourceLinkRe = regexp.MustCompile(`\]\(([^)\s]+)`)
+)
+
+// skillFrontmatter is the YAML frontmatter schema of a SKILL.md file.
+type skillFrontmatter struct {
+	Name          string            `yaml:"name"`
+	Description   string            `yaml:"description"`
+	License       string            `yaml:"license"`
+	Compatibility string            `yaml:"compatibility"`
+	Metadata      map[string]string `yaml:"metadata"`
+	AllowedTools  string            `yaml:"allowed-tools"`
+}
+
+var supportedSkillFrontmatterKeys = []string{"name", "description", "license", "compatibility", "metadata", "allowed-tools"}
+
+// skillFile is a parsed SKILL.md file. filePath is relative to the root of wherever the skill was loaded from, which
+// is either a local directory or a source repo.
+type skillFile struct {
+	filePath    string
+	body        string
+	frontmatter skillFrontmatter
+}
+
+// skillSlug returns the slug of a skill, which is the name of the directory containing its SKILL.md.
+func (s skillFile) skillSlug() string {
+	return path.Base(path.Dir(s.filePath))
+}
+
+func parseSkillFile(filePath string, content []byte) (skillFile, []error) {
+	yml := skillFrontmatter{}
+	body, errs := decodeFrontmatter(string(content), &yml, supportedSkillFrontmatterKeys)
+	if len(errs) != 0 {
+		return skillFile{}, errs
+	}
+	return skillFile{
+		filePath:    filePath,
+		body:        body,
+		frontmatter: yml,
+	}, nil
+}
+
+func validateSkillName(name string, slug string) []error {
+	if name == "" {
+		return []error{xerrors.New("missing required field \"name\"")}
+	}
+
+	var errs []error
+	if utf8.RuneCountInString(name) > maxSkillNameLength {
+		errs = append(errs, xerrors.Errorf("name %q exceeds %d characters", name, maxSkillNameLength))
+	}
+	if !skillNameRe.MatchString(name) {
+		errs = append(errs, xerrors.Errorf("name %q must only contain lowercase letters, digits, and single hyphens, and cannot start or end with a hyphen", name))
+	}
+	if name != slug {
+		errs = append(errs, xerrors.Errorf("name %q must match the skill's directory name %q", name, slug))
+	}
+	return errs
+}
+
+func validateSkillDescription(description string) error {
+	trimmed := strings.TrimSpace(description)
+	if trimmed == "" {
+		return xerrors.New("missing required field \"description\"")
+	}
+	if n := utf8.RuneCountInString(trimmed); n > maxSkillDescriptionLength {
+		return xerrors.Errorf("description is %d characters, which exceeds the %d character limit", n, maxSkillDescriptionLength)
+	}
+	return nil
+}
+
+// validateSkillResourceLinks checks that every relative link in the body of a SKILL.md points at a file inside the
+// skill's directory that exists. fileExists receives paths relative to the same root as the SKILL.md's filePath.
+func validateSkillResourceLinks(s skillFile, fileExists func(filePath string) bool) []error {
+	skillDir := path.Dir(s.filePath)
+
+	var errs []error
+	for _, match := range skillResourceLinkRe.FindAllStringSubmatch(s.body, -1) {
+		target := match[1]
+		if strings.HasPrefix(target, "#") || strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") {
+			continue
+		}
+		target, _, _ = strings.Cut(target, "#")
+		resolved := path.Join(skillDir, target)
+		if rel, err := filepath.Rel(skillDir, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+			errs = append(errs, xerrors.Errorf("referenced file %q is outside of the skill directory", target))
+			continue
+		}
+		if !fileExists(resolved) {
+			errs = append(errs, xerrors.Errorf("referenced file %q does not exist", target))
+		}
+	}
+	return errs
+}
+
+// validateSkillFile validates a parsed SKILL.md against the agent skills specification.
+func validateSkillFile(s skillFile, fileExists func(filePath string) bool) []error {
+	fm := s.frontmatter
+	errs := validateSkillName(fm.Name, s.skillSlug())
+	if err := validateSkillDescription(fm.Description); err != nil {
+		errs = append(errs, err)
+	}
+	if n := utf8.RuneCountInString(fm.Compatibility); n > maxSkillCompatibilityLength {
+		errs = append(errs, xerrors.Errorf("compatibility is %d characters, which exceeds the %d character limit", n, maxSkillCompatibilityLength))
+	}
+	errs = append(errs, validateSkillResourceLinks(s, fileExists)...)
+	return errs
+}
+
+// validateSkillContent parses and validates the SKILL.md at filePath.
+func validateSkillContent(filePath string, content []byte, fileExists func(filePath string) bool) []error {
+	s, errs := parseSkillFile(filePath, content)
+	if len(errs) != 0 {
+		return errs
+	}
+	return validateSkillFile(s, fileExists)
+}
+
+// validateLocalSkillDir validates the skill in a local directory.
+func validateLocalSkillDir(dir string) []error {
+	skillPath := filepath.Join(dir, skillFileName)
+	content, err := os.ReadFile(skillPath)
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	fileExists := func(filePath string) bool {
+		_, err := os.Stat(filePath)
+		return err == nil
+	}
+	// Use the absolute path, so that the directory name is known even when validating the current directory.
+	absPath, err := filepath.Abs(skillPath)
+	if err != nil {
+		return []error{err}
+	}
+	for _, err := range validateSkillContent(filepath.ToSlash(absPath), content, fileExists) {
+		errs = append(errs, addFilePathToError(skillPath, err))
+	}
+	return errs
+}
+
+// validateResolvedSourceSkills validates the SKILL.md of every skill in a source resolved from a git mirror.
+func validateResolvedSourceSkills(src resolvedSkillSource, skills map[string]string) []error {
+	files, err := src.listFiles()
+	if err != nil {
+		return []error{err}
+	}
+	// Directories are not listed by git, so a path exists if it is a file or a prefix of one.
+	fileExists := func(filePath string) bool {
+		return slices.ContainsFunc(files, func(f string) bool {
+			return f == filePath || strings.HasPrefix(f, filePath+"/")
+		})
+	}
+
+	var slugs []string
+	for slug := range skills {
+		slugs = append(slugs, slug)
+	}
+	slices.Sort(slugs)
+
+	var errs []error
+	for _, slug := range slugs {
+		skillPath := skills[slug]
+		content, err := src.readFile(skillPath)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, err := range validateSkillContent(skillPath, content, fileExists) {
+			errs = append(errs, xerrors.Errorf("%s@%s:%s: %v", src.repo, src.ref, skillPath, err))
+		}
+	}
+	return errs
+}
+
+// runSkillCommand validates the local skill directories given as arguments.
+func runSkillCommand(args []string) error {
+	if len(args) == 0 {
+		return xerrors.New("expected at least one skill directory")
+	}
+
+	var errs []error
+	for _, dir := range args {
+		errs = append(errs, validateLocalSkillDir(dir)...)
+	}
+	if len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseSkillSources,
+			errors: errs,
+		}
+	}
+	logger.Info(context.Background(), "all skills are valid", "num_skills", len(args))
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateSkillContent(t *testing.T) {
+	t.Parallel()
+
+	existingFiles := []string{"skills/setup/SKILL.md", "skills/setup/scripts/install.sh", "skills/setup/references/guide.md"}
+	fileExists := func(filePath string) bool {
+		return slices.Contains(existingFiles, filePath)
+	}
+
+	testCases := []struct {
+		name         string
+		content      string
+		expectedErrs []string
+	}{
+		{
+			name: "valid skill",
+			content: "---\nname: setup\ndescription: Install Coder.\nlicense: MIT\nmetadata:\n  author: coder\n---\n\n" +
+				"Run [the installer](scripts/install.sh), then read [the guide](./references/guide.md#usage) and [the docs](https://coder.com/docs).\n",
+		},
+		{
+			name:         "missing frontmatter",
+			content:      "# Setup\n",
+			expectedErrs: []string{"failed to parse frontmatter"},
+		},
+		{
+			name:         "missing required fields",
+			content:      "---\nlicense: MIT\n---\n",
+			expectedErrs: []string{`missing required field "name"`, `missing required field "description"`},
+		},
+		{
+			name:         "unknown key",
+			content:      "---\nname: setup\ndescription: Install Coder.\nversion: 1\n---\n",
+			expectedErrs: []string{`detected unknown key "version"`},
+		},
+		{
+			name:    "name does not match directory",
+			content: "---\nname: Install-Coder\ndescription: Install Coder.\n---\n",
+			expectedErrs: []string{
+				`name "Install-Coder" must only contain lowercase letters`,
+				`name "Install-Coder" must match the skill's directory name "setup"`,
+			},
+		},
+		{
+			name:         "description too long",
+			content:      "---\nname: setup\ndescription: " + strings.Repeat("a", maxSkillDescriptionLength+1) + "\n---\n",
+			expectedErrs: []string{"description is 1025 characters, which exceeds the 1024 character limit"},
+		},
+		{
+			name:         "missing and escaping resource links",
+			content:      "---\nname: setup\ndescription: Install Coder.\n---\n\nSee [missing](scripts/missing.sh) and [outside](../other/SKILL.md).\n",
+			expectedErrs: []string{`referenced file "scripts/missing.sh" does not exist`, `referenced file "../other/SKILL.md" is outside of the skill directory`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			errs := validateSkillContent("skills/setup/SKILL.md", []byte(tc.content), fileExists)
+			if len(errs) != len(tc.expectedErrs) {
+				t.Fatalf("Expected %d errors, got: %v", len(tc.expectedErrs), errs)
+			}
+			for i, expected := range tc.expectedErrs {
+				if !strings.Contains(errs[i].Error(), expected) {
+					t.Errorf("Expected error containing %q, got: %v", expected, errs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateLocalSkillDir(t *testing.T) {
+	t.Parallel()
+
+	skillDir := filepath.Join(t.TempDir(), "deploy")
+	if err := os.MkdirAll(filepath.Join(skillDir, "scripts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(skillDir, "scripts", "run.sh"), []byte("#!/bin/sh\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\nname: deploy\ndescription: Deploy a workspace.\n---\n\nRun [the script](scripts/run.sh) from [the scripts directory](scripts).\n"
+	if err := os.WriteFile(filepath.Join(skillDir, skillFileName), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if errs := validateLocalSkillDir(skillDir); len(errs) != 0 {
+		t.Errorf("Unexpected validation errors: %v", errs)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path"
+	"slices"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// skillFileName is the file that marks a directory of a source repo as a skill.
+const skillFileName = "SKILL.md"
+
+// skillSourceMirror resolves skill sources against bare git clones on disk, so that sources can be verified without
+// network access. A source "owner/repo" is looked up at "<dir>/owner/repo.git", falling back to "<dir>/owner/repo".
+type skillSourceMirror struct {
+	dir string
+}
+
+// resolvedSkillSource is a skill source whose ref has been resolved to a commit in a local mirror.
+type resolvedSkillSource struct {
+	repo   string
+	ref    string
+	gitDir string
+	commit string
+}
+
+// splitSkillsRepoSpec splits an "owner/repo@ref" spec into the repo and the ref. Sources without a ref track the
+// default branch of the repo, which is what HEAD points at in a bare clone.
+func splitSkillsRepoSpec(spec string) (repo string, ref string) {
+	repo, ref, hasRef := strings.Cut(spec, "@")
+	if !hasRef {
+		ref = "HEAD"
+	}
+	return repo, ref
+}
+
+func (m skillSourceMirror) gitDir(repo string) (string, error) {
+	for _, candidate := range []string{path.Join(m.dir, repo+".git"), path.Join(m.dir, repo)} {
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate, nil
+		}
+	}
+	return "", xerrors.Errorf("no mirror of %q found in %q", repo, m.dir)
+}
+
+// resolve finds the mirror of a source and resolves its ref to a commit.
+func (m skillSourceMirror) resolve(spec string) (resolvedSkillSource, error) {
+	repo, ref := splitSkillsRepoSpec(spec)
+	gitDir, err := m.gitDir(repo)
+	if err != nil {
+		return resolvedSkillSource{}, err
+	}
+
+	out, err := exec.Command("git", "--git-dir", gitDir, "rev-parse", "--verify", "--quiet", "--end-of-options", ref+"^{commit}").Output()
+	if err != nil {
+		return resolvedSkillSource{}, xerrors.Errorf("ref %q does not exist in %q", ref, repo)
+	}
+	return resolvedSkillSource{
+		repo:   repo,
+		ref:    ref,
+		gitDir: gitDir,
+		commit: strings.TrimSpace(string(out)),
+	}, nil
+}
+
+// listFiles returns the path of every file in the source at the resolved commit.
+func (src resolvedSkillSource) listFiles() ([]string, error) {
+	out, err := exec.Command("git", "--git-dir", src.gitDir, "ls-tree", "-r", "--name-only", src.commit).Output()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to list files of %q at %q: %v", src.repo, src.ref, err)
+	}
+	return strings.Split(strings.TrimSpace(string(out)), "\n"), nil
+}
+
+// listSkills returns the path of the SKILL.md file of every skill in the source, keyed by skill slug. The slug of a
+// skill is the name of the directory containing its SKILL.md.
+func (src resolvedSkillSource) listSkills() (map[string]string, error) {
+	files, err := src.listFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	skills := map[string]string{}
+	for _, filePath := range files {
+		if path.Base(filePath) != skillFileName || path.Dir(filePath) == "." {
+			continue
+		}
+		skills[path.Base(path.Dir(filePath))] = filePath
+	}
+	return skills, nil
+}
+
+// readFile returns the contents of a file in the source at the resolved commit.
+func (src resolvedSkillSource) readFile(filePath string) ([]byte, error) {
+	out, err := exec.Command("git", "--git-dir", src.gitDir, "show", src.commit+":"+filePath).Output()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read %q from %q at %q: %v", filePath, src.repo, src.ref, err)
+	}
+	return out, nil
+}
+
+// validateSkillSourceAgainstMirror checks that a source's ref resolves, that every skill override refers to a skill
+// that exists upstream, that every upstream skill has registry metadata, and that every upstream SKILL.md is valid.
+func validateSkillSourceAgainstMirror(mirror skillSourceMirror, src skillSource) []error {
+	resolved, err := mirror.resolve(src.Repo)
+	if err != nil {
+		return []error{err}
+	}
+	upstream, err := resolved.listSkills()
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	var slugs []string
+	for slug := range src.Skills {
+		slugs = append(slugs, slug)
+	}
+	slices.Sort(slugs)
+	for _, slug := range slugs {
+		if _, ok := upstream[slug]; !ok {
+			errs = append(errs, xerrors.Errorf("skill %q has metadata but does not exist in %q at %q", slug, resolved.repo, resolved.ref))
+		}
+	}
+
+	var upstreamSlugs []string
+	for slug := range upstream {
+		upstreamSlugs = append(upstreamSlugs, slug)
+	}
+	slices.Sort(upstreamSlugs)
+	for _, slug := range upstreamSlugs {
+		if _, ok := src.Skills[slug]; !ok {
+			errs = append(errs, xerrors.Errorf("skill %q exists in %q at %q but has no metadata under sources.skills", slug, resolved.repo, resolved.ref))
+		}
+	}
+	return append(errs, validateResolvedSourceSkills(resolved, upstream)...)
+}
+
+// validateAllSkillSourceMirrors verifies every skill source in the registry against the mirrors in mirrorDir. It is
+// skipped when no mirror directory is configured.
+func validateAllSkillSourceMirrors(mirrorDir string) error {
+	if mirrorDir == "" {
+		return nil
+	}
+	if info, err := os.Stat(mirrorDir); err != nil || !info.IsDir() {
+		return xerrors.Errorf("skills mirror directory %q does not exist", mirrorDir)
+	}
+
+	allReadmeFiles, err := aggregateSkillsReadmeFiles()
+	if err != nil {
+		return err
+	}
+	readmes, err := parseCoderSkillsReadmeFiles(allReadmeFiles)
+	if err != nil {
+		return err
+	}
+
+	mirror := skillSourceMirror{dir: mirrorDir}
+	var errs []error
+	for _, rm := range readmes {
+		for i, src := range rm.frontmatter.Sources {
+			for _, err := range validateSkillSourceAgainstMirror(mirror, src) {
+				errs = append(errs, addFilePathToError(rm.filePath, xerrors.Errorf("sources[%d]: %v", i, err)))
+			}
+		}
+	}
+	if len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseSkillSources,
+			errors: errs,
+		}
+	}
+	logger.Info(context.Background(), "verified all skill sources against local mirrors", "mirror_dir", mirrorDir)
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestSkillMirror creates a mirror directory holding a bare clone of "owner/repo" with the given files committed
+// on its main branch, and a "v1" tag pointing at that commit.
+func newTestSkillMirror(t *testing.T, repo string, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	git := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+
+	workDir := t.TempDir()
+	git(workDir, "init", "--quiet", "--initial-branch=main")
+	for name, content := range files {
+		filePath := filepath.Join(workDir, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git(workDir, "add", "-A")
+	git(workDir, "commit", "--quiet", "-m", "Add skills")
+	git(workDir, "tag", "v1")
+
+	mirrorDir := t.TempDir()
+	git(mirrorDir, "clone", "--quiet", "--bare", workDir, filepath.Join(mirrorDir, repo+".git"))
+	return mirrorDir
+}
+
+func TestValidateSkillSourceAgainstMirror(t *testing.T) {
+	t.Parallel()
+
+	mirrorDir := newTestSkillMirror(t, "acme/skills", map[string]string{
+		"README.md":              "# Skills\n",
+		"SKILL.md":               "not a skill directory\n",
+		"skills/setup/SKILL.md":  "---\nname: setup\ndescription: Set things up.\n---\n",
+		"skills/deploy/SKILL.md": "---\nname: deploy\ndescription: Deploy things. Run [the script](run.sh).\n---\n\nRun [the script](run.sh) or [the missing one](missing.sh).\n",
+		"skills/deploy/run.sh":   "#!/bin/sh\n",
+	})
+	mirror := skillSourceMirror{dir: mirrorDir}
+
+	testCases := []struct {
+		name         string
+		source       skillSource
+		expectedErrs []string
+	}{
+		{
+			name:         "every skill has metadata",
+			source:       skillSource{Repo: "acme/skills@main", Skills: map[string]skillOverride{"setup": {}, "deploy": {}}},
+			expectedErrs: []string{`acme/skills@main:skills/deploy/SKILL.md: referenced file "missing.sh" does not exist`},
+		},
+		{
+			name:         "default branch and tags resolve",
+			source:       skillSource{Repo: "acme/skills", Skills: map[string]skillOverride{"setup": {}, "deploy": {}}},
+			expectedErrs: []string{`referenced file "missing.sh" does not exist`},
+		},
+		{
+			name:         "missing mirror",
+			source:       skillSource{Repo: "acme/other@main"},
+			expectedErrs: []string{`no mirror of "acme/other"`},
+		},
+		{
+			name:         "missing ref",
+			source:       skillSource{Repo: "acme/skills@v2"},
+			expectedErrs: []string{`ref "v2" does not exist in "acme/skills"`},
+		},
+		{
+			name:   "override for missing skill and skill without metadata",
+			source: skillSource{Repo: "acme/skills@v1", Skills: map[string]skillOverride{"setup": {}, "teardown": {}}},
+			expectedErrs: []string{
+				`skill "teardown" has metadata but does not exist in "acme/skills" at "v1"`,
+				`skill "deploy" exists in "acme/skills" at "v1" but has no metadata`,
+				`referenced file "missing.sh" does not exist`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			errs := validateSkillSourceAgainstMirror(mirror, tc.source)
+			if len(errs) != len(tc.expectedErrs) {
+				t.Fatalf("Expected %d errors, got: %v", len(tc.expectedErrs), errs)
+			}
+			for i, expected := range tc.expectedErrs {
+				if !strings.Contains(errs[i].Error(), expected) {
+					t.Errorf("Expected error containing %q, got: %v", expected, errs[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// skillsIndexPath is where the agent skills well-known discovery index is written. The Registry site serves it
+	// as-is from /.well-known/skills/index.json.
+	skillsIndexPath = "./.well-known/skills/index.json"
+
+	// registrySiteURL is the base URL of the Registry site.
+	registrySiteURL = "https://registry.coder.com"
+
+	// registryRawContentURL is the base URL that files on the repo's main branch are served from. Icons in the
+	// discovery index are published under it, since clients of the index have no checkout to resolve paths against.
+	registryRawContentURL = "https://raw.githubusercontent.com/coder/registry/main"
+)
+
+// skillsIndexEntry describes a single skill in the well-known discovery index.
+type skillsIndexEntry struct {
+	Namespace   string   `json:"namespace"`
+	Name        string   `json:"name"`
+	DisplayName string   `json:"display_name"`
+	Description string   `json:"description,omitempty"`
+	Icon        string   `json:"icon,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Source      string   `json:"source"`
+	URL         string   `json:"url"`
+}
+
+// skillsIndex is the document served from the agent skills well-known discovery endpoint.
+type skillsIndex struct {
+	Skills []skillsIndexEntry `json:"skills"`
+}
+
+// skillPageURL returns the Registry site page of a skill.
+func skillPageURL(namespace string, slug string) string {
+	return registrySiteURL + "/skills/" + namespace + "/" + slug
+}
+
+// skillIconURL converts an icon URL relative to a skills README into the absolute URL the icon is served from.
+func skillIconURL(readmeFilePath string, iconURL string) string {
+	if iconURL == "" {
+		return ""
+	}
+	return registryRawContentURL + "/" + path.Join(path.Dir(readmeFilePath), iconURL)
+}
+
+// buildSkillsIndex builds the discovery index from every skills README. Overrides replace the defaults for a skill:
+// the slug as display name, and the namespace-level icon. Icons are written in READMEs as paths relative to the README,
+// and are published as absolute URLs under registryRawContentURL, e.g.
+// "https://raw.githubusercontent.com/coder/registry/main/.icons/coder.svg" for "../../../.icons/coder.svg".
+func buildSkillsIndex(readmes []coderSkillsReadme) skillsIndex {
+	index := skillsIndex{Skills: []skillsIndexEntry{}}
+	for _, rm := range readmes {
+		namespace := resourceNamespace(rm.filePath)
+		for _, src := range rm.frontmatter.Sources {
+			for slug, override := range src.Skills {
+				entry := skillsIndexEntry{
+					Namespace:   namespace,
+					Name:        slug,
+					DisplayName: slug,
+					Description: strings.TrimSpace(override.Description),
+					Icon:        skillIconURL(rm.filePath, rm.frontmatter.Icon),
+					Tags:        override.Tags,
+					Source:      src.Repo,
+					URL:         skillPageURL(namespace, slug),
+				}
+				if override.DisplayName != "" {
+					entry.DisplayName = override.DisplayName
+				}
+				if override.Icon != "" {
+					entry.Icon = skillIconURL(rm.filePath, override.Icon)
+				}
+				index.Skills = append(index.Skills, entry)
+			}
+		}
+	}
+
+	slices.SortFunc(index.Skills, func(a, b skillsIndexEntry) int {
+		if c := strings.Compare(a.Namespace, b.Namespace); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return index
+}
+
+func renderSkillsIndex(index skillsIndex) ([]byte, error) {
+	content, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+// validateSkillsIndex validates that the committed discovery index matches the one generated from the skills READMEs.
+func validateSkillsIndex(readmes []coderSkillsReadme) error {
+	generated, err := renderSkillsIndex(buildSkillsIndex(readmes))
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	committed, err := os.ReadFile(skillsIndexPath)
+	if err != nil {
+		errs = append(errs, addFilePathToError(skillsIndexPath, err))
+	} else if string(committed) != string(generated) {
+		errs = append(errs, xerrors.Errorf("%q: file is out of date with the skills READMEs; regenerate it with \"./readmevalidation skills-index\"", skillsIndexPath))
+	}
+	if len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseCrossReference,
+			errors: errs,
+		}
+	}
+	return nil
+}
+
+func runSkillsIndexCommand(_ []string) error {
+	allReadmeFiles, err := aggregateSkillsReadmeFiles()
+	if err != nil {
+		return err
+	}
+	readmes, err := parseCoderSkillsReadmeFiles(allReadmeFiles)
+	if err != nil {
+		return err
+	}
+	if err := validateAllCoderSkillsReadmes(readmes); err != nil {
+		return err
+	}
+
+	index := buildSkillsIndex(readmes)
+	content, err := renderSkillsIndex(index)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(skillsIndexPath), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(skillsIndexPath, content, 0o644); err != nil {
+		return err
+	}
+	logger.Info(context.Background(), "generated skills discovery index", "path", skillsIndexPath, "num_skills", len(index.Skills))
+	return nil
+}
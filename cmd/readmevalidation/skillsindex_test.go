@@ -0,0 +1,76 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBuildSkillsIndex(t *testing.T) {
+	t.Parallel()
+
+	readmes := []coderSkillsReadme{
+		{
+			filePath: "registry/zeta/skills/README.md",
+			frontmatter: coderSkillsFrontmatter{
+				Sources: []skillSource{{Repo: "zeta/skills", Skills: map[string]skillOverride{"deploy": {}}}},
+			},
+		},
+		{
+			filePath: "registry/coder/skills/README.md",
+			frontmatter: coderSkillsFrontmatter{
+				Icon: "../../../.icons/coder.svg",
+				Sources: []skillSource{
+					{Repo: "coder/skills@v1", Skills: map[string]skillOverride{
+						"templates": {},
+						"setup": {
+							DisplayName: "Coder Setup",
+							Description: " Install Coder. ",
+							Icon:        "../../../.icons/setup.svg",
+							Tags:        []string{"coder", "setup"},
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	expected := []skillsIndexEntry{
+		{
+			Namespace:   "coder",
+			Name:        "setup",
+			DisplayName: "Coder Setup",
+			Description: "Install Coder.",
+			Icon:        "https://raw.githubusercontent.com/coder/registry/main/.icons/setup.svg",
+			Tags:        []string{"coder", "setup"},
+			Source:      "coder/skills@v1",
+			URL:         "https://registry.coder.com/skills/coder/setup",
+		},
+		{
+			Namespace:   "coder",
+			Name:        "templates",
+			DisplayName: "templates",
+			Icon:        "https://raw.githubusercontent.com/coder/registry/main/.icons/coder.svg",
+			Source:      "coder/skills@v1",
+			URL:         "https://registry.coder.com/skills/coder/templates",
+		},
+		{
+			Namespace:   "zeta",
+			Name:        "deploy",
+			DisplayName: "deploy",
+			Source:      "zeta/skills",
+			URL:         "https://registry.coder.com/skills/zeta/deploy",
+		},
+	}
+
+	// Skills are stored in maps, so build the index several times to catch nondeterministic ordering.
+	for range 5 {
+		index := buildSkillsIndex(readmes)
+		if !slices.EqualFunc(index.Skills, expected, func(a, b skillsIndexEntry) bool {
+			return a.Namespace == b.Namespace && a.Name == b.Name && a.DisplayName == b.DisplayName &&
+				a.Description == b.Description && a.Icon == b.Icon && slices.Equal(a.Tags, b.Tags) &&
+				a.Source == b.Source && a.URL == b.URL
+		}) {
+			t.Fatalf("Unexpected index:\n got: %+v\nwant: %+v", index.Skills, expected)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+)
+
+const skillsRefPolicyPath = "./.github/skills-ref-policy.yaml"
+
+// skillsRefLevel is how strictly a skill source must pin the ref of its repo.
+type skillsRefLevel string
+
+const (
+	skillsRefLevelBranch skillsRefLevel = "branch"
+	skillsRefLevelTag    skillsRefLevel = "tag"
+	skillsRefLevelSha    skillsRefLevel = "sha"
+)
+
+var skillsRefLevels = []skillsRefLevel{skillsRefLevelBranch, skillsRefLevelTag, skillsRefLevelSha}
+
+var (
+	// skillsRefShaRe matches full commit SHAs. Abbreviated SHAs are rejected, since they can become ambiguous.
+	skillsRefShaRe = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+	// skillsRefTagRe matches version tags. Branches can't be told apart from arbitrary tag names without access to
+	// the source repo, so only version-shaped tags are accepted.
+	skillsRefTagRe = regexp.MustCompile(`^v?[0-9]+(\.[0-9]+){0,2}([-+][0-9A-Za-z.-]+)?$`)
+)
+
+// skillsRefPolicy is the schema of the skills ref policy file.
+type skillsRefPolicy struct {
+	Default    skillsRefLevel            `yaml:"default"`
+	Namespaces map[string]skillsRefLevel `yaml:"namespaces"`
+}
+
+func loadSkillsRefPolicy() (skillsRefPolicy, error) {
+	policy := skillsRefPolicy{}
+	content, err := os.ReadFile(skillsRefPolicyPath)
+	if err != nil {
+		return policy, err
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&policy); err != nil {
+		return policy, addFilePathToError(skillsRefPolicyPath, err)
+	}
+
+	var errs []error
+	if !slices.Contains(skillsRefLevels, policy.Default) {
+		errs = append(errs, xerrors.Errorf("%q: default level %q is not one of %v", skillsRefPolicyPath, policy.Default, skillsRefLevels))
+	}
+	for namespace, level := range policy.Namespaces {
+		if !slices.Contains(skillsRefLevels, level) {
+			errs = append(errs, xerrors.Errorf("%q: level %q for namespace %q is not one of %v", skillsRefPolicyPath, level, namespace, skillsRefLevels))
+		}
+	}
+	if len(errs) != 0 {
+		return policy, validationPhaseError{
+			phase:  validationPhaseReadme,
+			errors: errs,
+		}
+	}
+	return policy, nil
+}
+
+// levelFor returns the ref level that applies to a namespace.
+func (p skillsRefPolicy) levelFor(namespace string) skillsRefLevel {
+	if level, ok := p.Namespaces[namespace]; ok {
+		return level
+	}
+	return p.Default
+}
+
+// validateSkillsRefPin checks that a source's repo spec pins a ref that satisfies the given level.
+func validateSkillsRefPin(repoSpec string, level skillsRefLevel) error {
+	_, ref, hasRef := strings.Cut(repoSpec, "@")
+	switch level {
+	case skillsRefLevelSha:
+		if !hasRef || !skillsRefShaRe.MatchString(ref) {
+			return xerrors.Errorf("repo %q must pin a full 40-character commit SHA (e.g. owner/repo@<sha>)", repoSpec)
+		}
+	case skillsRefLevelTag:
+		if !hasRef || (!skillsRefTagRe.MatchString(ref) && !skillsRefShaRe.MatchString(ref)) {
+			return xerrors.Errorf("repo %q must pin a version tag such as v1.2.0 or a full commit SHA", repoSpec)
+		}
+	}
+	return nil
+}
+
+// validateSkillsRefPolicy checks every source of every skills README against the ref policy.
+func validateSkillsRefPolicy(policy skillsRefPolicy, readmes []coderSkillsReadme) []error {
+	var errs []error
+	namespaces := map[string]bool{}
+	for _, rm := range readmes {
+		namespace := resourceNamespace(rm.filePath)
+		namespaces[namespace] = true
+		level := policy.levelFor(namespace)
+		for i, src := range rm.frontmatter.Sources {
+			if err := validateSkillsRefPin(src.Repo, level); err != nil {
+				errs = append(errs, addFilePathToError(rm.filePath, xerrors.Errorf("sources[%d]: %v (policy for namespace %q: %s)", i, err, namespace, level)))
+			}
+		}
+	}
+
+	var exceptions []string
+	for namespace := range policy.Namespaces {
+		exceptions = append(exceptions, namespace)
+	}
+	slices.Sort(exceptions)
+	for _, namespace := range exceptions {
+		if !namespaces[namespace] {
+			errs = append(errs, xerrors.Errorf("%q: exception for namespace %q, which has no skills README", skillsRefPolicyPath, namespace))
+		}
+	}
+	return errs
+}
+
+// validateSkillsSlugCollisions reports skill slugs that are exposed more than once, either by two sources of the same
+// namespace or by two different namespaces. Skills are often referred to by slug alone, so a slug must identify a
+// single skill across the whole registry.
+func validateSkillsSlugCollisions(readmes []coderSkillsReadme) []error {
+	type slugOwner struct {
+		filePath string
+		source   int
+	}
+	owners := map[string]slugOwner{}
+
+	var errs []error
+	for _, rm := range readmes {
+		for i, src := range rm.frontmatter.Sources {
+			var slugs []string
+			for slug := range src.Skills {
+				slugs = append(slugs, slug)
+			}
+			slices.Sort(slugs)
+
+			for _, slug := range slugs {
+				prev, exists := owners[slug]
+				if !exists {
+					owners[slug] = slugOwner{filePath: rm.filePath, source: i}
+					continue
+				}
+				if prev.filePath == rm.filePath {
+					errs = append(errs, addFilePathToError(rm.filePath, xerrors.Errorf("sources[%d]: skill %q is already declared by sources[%d]", i, slug, prev.source)))
+					continue
+				}
+				errs = append(errs, addFilePathToError(rm.filePath, xerrors.Errorf("sources[%d]: skill %q is already declared by %q", i, slug, prev.filePath)))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateSkillsRefPin(t *testing.T) {
+	t.Parallel()
+
+	sha := "0123456789abcdef0123456789abcdef01234567"
+	testCases := []struct {
+		repo    string
+		level   skillsRefLevel
+		wantErr bool
+	}{
+		{repo: "acme/skills", level: skillsRefLevelBranch},
+		{repo: "acme/skills@main", level: skillsRefLevelBranch},
+		{repo: "acme/skills@main", level: skillsRefLevelTag, wantErr: true},
+		{repo: "acme/skills", level: skillsRefLevelTag, wantErr: true},
+		{repo: "acme/skills@v1.2.0", level: skillsRefLevelTag},
+		{repo: "acme/skills@1.2", level: skillsRefLevelTag},
+		{repo: "acme/skills@v2.0.0-rc.1", level: skillsRefLevelTag},
+		{repo: "acme/skills@" + sha, level: skillsRefLevelTag},
+		{repo: "acme/skills@v1.2.0", level: skillsRefLevelSha, wantErr: true},
+		{repo: "acme/skills@" + sha[:7], level: skillsRefLevelSha, wantErr: true},
+		{repo: "acme/skills@" + sha, level: skillsRefLevelSha},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.repo+"/"+string(tc.level), func(t *testing.T) {
+			t.Parallel()
+
+			err := validateSkillsRefPin(tc.repo, tc.level)
+			if tc.wantErr && err == nil {
+				t.Error("Expected error, got none")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateSkillsRefPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy := skillsRefPolicy{
+		Default:    skillsRefLevelTag,
+		Namespaces: map[string]skillsRefLevel{"coder": skillsRefLevelBranch, "removed": skillsRefLevelBranch},
+	}
+	readmes := []coderSkillsReadme{
+		{filePath: "registry/acme/skills/README.md", frontmatter: coderSkillsFrontmatter{Sources: []skillSource{{Repo: "acme/skills@main"}}}},
+		{filePath: "registry/coder/skills/README.md", frontmatter: coderSkillsFrontmatter{Sources: []skillSource{{Repo: "coder/skills@main"}}}},
+	}
+
+	errs := validateSkillsRefPolicy(policy, readmes)
+	expectedErrs := []string{
+		`"registry/acme/skills/README.md": sources[0]: repo "acme/skills@main" must pin a version tag`,
+		`exception for namespace "removed", which has no skills README`,
+	}
+	if len(errs) != len(expectedErrs) {
+		t.Fatalf("Expected %d errors, got: %v", len(expectedErrs), errs)
+	}
+	for i, expected := range expectedErrs {
+		if !strings.Contains(errs[i].Error(), expected) {
+			t.Errorf("Expected error containing %q, got: %v", expected, errs[i])
+		}
+	}
+}
+
+func TestValidateSkillsSlugCollisions(t *testing.T) {
+	t.Parallel()
+
+	readmes := []coderSkillsReadme{
+		{
+			filePath: "registry/acme/skills/README.md",
+			frontmatter: coderSkillsFrontmatter{Sources: []skillSource{
+				{Repo: "acme/skills@v1", Skills: map[string]skillOverride{"setup": {}, "deploy": {}}},
+				{Repo: "acme/more-skills@v1", Skills: map[string]skillOverride{"deploy": {}}},
+			}},
+		},
+		{
+			filePath: "registry/zeta/skills/README.md",
+			frontmatter: coderSkillsFrontmatter{Sources: []skillSource{
+				{Repo: "zeta/skills@v1", Skills: map[string]skillOverride{"setup": {}, "lint": {}}},
+			}},
+		},
+	}
+
+	errs := validateSkillsSlugCollisions(readmes)
+	expectedErrs := []string{
+		`"registry/acme/skills/README.md": sources[1]: skill "deploy" is already declared by sources[0]`,
+		`"registry/zeta/skills/README.md": sources[0]: skill "setup" is already declared by "registry/acme/skills/README.md"`,
+	}
+	if len(errs) != len(expectedErrs) {
+		t.Fatalf("Expected %d errors, got: %v", len(expectedErrs), errs)
+	}
+	for i, expected := range expectedErrs {
+		if !strings.Contains(errs[i].Error(), expected) {
+			t.Errorf("Expected error containing %q, got: %v", expected, errs[i])
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/xerrors"
+)
+
+// availableSkillsHeading is the heading of the section of a skills README that lists every skill it declares.
+const availableSkillsHeading = "## Available Skills"
+
+// skillsTableLinkRe matches the Markdown link in the first column of an Available Skills table row.
+var skillsTableLinkRe = regexp.MustCompile(`^\|\s*\[[^\]]*\]\(([^)\s]+)\)`)
+
+// generateAvailableSkillsTable renders the Available Skills table of a skills README. Columns are padded the same way
+// Prettier pads Markdown tables, so that formatting the README does not make it drift.
+func generateAvailableSkillsTable(rm coderSkillsReadme) string {
+	rows := [][2]string{{"Skill", "Description"}}
+	for _, entry := range buildSkillsIndex([]coderSkillsReadme{rm}).Skills {
+		rows = append(rows, [2]string{"[" + entry.DisplayName + "](" + entry.URL + ")", entry.Description})
+	}
+
+	widths := [2]int{3, 3}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	var out strings.Builder
+	writeRow := func(cells [2]string) {
+		out.WriteString("|")
+		for i, cell := range cells {
+			out.WriteString(" " + cell + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)) + " |")
+		}
+		out.WriteString("\n")
+	}
+	writeRow(rows[0])
+	writeRow([2]string{strings.Repeat("-", widths[0]), strings.Repeat("-", widths[1])})
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+	return out.String()
+}
+
+// findAvailableSkillsTable returns the line range [start, end) of the table under the Available Skills heading. The
+// range is empty when the heading exists but has no table, and found is false when there is no heading at all.
+func findAvailableSkillsTable(lines []string) (start int, end int, found bool) {
+	headingIdx := slices.IndexFunc(lines, func(line string) bool {
+		return strings.TrimSpace(line) == availableSkillsHeading
+	})
+	if headingIdx == -1 {
+		return 0, 0, false
+	}
+
+	start = headingIdx + 1
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	end = start
+	for end < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[end]), "|") {
+		end++
+	}
+	return start, end, true
+}
+
+// validateAvailableSkillsTable validates that the Available Skills table of a skills README links to exactly the
+// skills declared in its frontmatter, and matches the generated table.
+func validateAvailableSkillsTable(rm coderSkillsReadme) []error {
+	lines := strings.Split(rm.body, "\n")
+	start, end, found := findAvailableSkillsTable(lines)
+	if !found {
+		return []error{xerrors.Errorf("README must have an %q section listing every skill", availableSkillsHeading)}
+	}
+
+	namespace := resourceNamespace(rm.filePath)
+	expectedURLs := map[string]string{}
+	for _, src := range rm.frontmatter.Sources {
+		for slug := range src.Skills {
+			expectedURLs[skillPageURL(namespace, slug)] = slug
+		}
+	}
+
+	var errs []error
+	listedURLs := map[string]bool{}
+	for _, line := range lines[start:end] {
+		match := skillsTableLinkRe.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		listedURLs[match[1]] = true
+		if _, ok := expectedURLs[match[1]]; !ok {
+			errs = append(errs, xerrors.Errorf("%q table links to %q, which is not a skill declared under sources[].skills", availableSkillsHeading, match[1]))
+		}
+	}
+
+	var missing []string
+	for url, slug := range expectedURLs {
+		if !listedURLs[url] {
+			missing = append(missing, slug)
+		}
+	}
+	slices.Sort(missing)
+	for _, slug := range missing {
+		errs = append(errs, xerrors.Errorf("%q table is missing skill %q (expected a link to %q)", availableSkillsHeading, slug, skillPageURL(namespace, slug)))
+	}
+
+	if len(errs) == 0 && strings.Join(lines[start:end], "\n")+"\n" != generateAvailableSkillsTable(rm) {
+		errs = append(errs, xerrors.Errorf("%q table is out of date with the frontmatter; regenerate it with \"./readmevalidation skills-table\"", availableSkillsHeading))
+	}
+	return errs
+}
+
+// replaceAvailableSkillsTable returns the README text with its Available Skills table replaced by the generated
+// one, appending the section if the README does not have it yet.
+func replaceAvailableSkillsTable(rawText string, rm coderSkillsReadme) string {
+	table := strings.TrimSuffix(generateAvailableSkillsTable(rm), "\n")
+	lines := strings.Split(rawText, "\n")
+	start, end, found := findAvailableSkillsTable(lines)
+	if !found {
+		return strings.TrimRight(rawText, "\n") + "\n\n" + availableSkillsHeading + "\n\n" + table + "\n"
+	}
+
+	replaced := slices.Concat(lines[:start], []string{table}, lines[end:])
+	if start == end {
+		// The heading had no table, so separate the new table from whatever follows it.
+		replaced = slices.Concat(lines[:start], []string{table, ""}, lines[end:])
+	}
+	return strings.Join(replaced, "\n")
+}
+
+func runSkillsTableCommand(_ []string) error {
+	allReadmeFiles, err := aggregateSkillsReadmeFiles()
+	if err != nil {
+		return err
+	}
+	readmes, err := parseCoderSkillsReadmeFiles(allReadmeFiles)
+	if err != nil {
+		return err
+	}
+
+	for i, rm := range readmes {
+		updated := replaceAvailableSkillsTable(allReadmeFiles[i].rawText, rm)
+		if updated == allReadmeFiles[i].rawText {
+			continue
+		}
+		if err := os.WriteFile(rm.filePath, []byte(updated), 0o644); err != nil {
+			return err
+		}
+		logger.Info(context.Background(), "updated Available Skills table", "path", rm.filePath)
+	}
+	return nil
+}
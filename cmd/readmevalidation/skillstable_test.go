@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func newTestSkillsReadme(body string, slugs ...string) coderSkillsReadme {
+	skills := map[string]skillOverride{}
+	for _, slug := range slugs {
+		skills[slug] = skillOverride{DisplayName: strings.ToUpper(slug[:1]) + slug[1:], Description: "Does " + slug + "."}
+	}
+	return coderSkillsReadme{
+		filePath: "registry/acme/skills/README.md",
+		body:     body,
+		frontmatter: coderSkillsFrontmatter{
+			Sources: []skillSource{{Repo: "acme/skills@v1", Skills: skills}},
+		},
+	}
+}
+
+func TestGenerateAvailableSkillsTable(t *testing.T) {
+	t.Parallel()
+
+	expected := "" +
+		"| Skill                                                   | Description  |\n" +
+		"| ------------------------------------------------------- | ------------ |\n" +
+		"| [Deploy](https://registry.coder.com/skills/acme/deploy) | Does deploy. |\n" +
+		"| [Setup](https://registry.coder.com/skills/acme/setup)   | Does setup.  |\n"
+
+	got := generateAvailableSkillsTable(newTestSkillsReadme("", "setup", "deploy"))
+	if got != expected {
+		t.Errorf("Unexpected table:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestValidateAvailableSkillsTable(t *testing.T) {
+	t.Parallel()
+
+	upToDate := generateAvailableSkillsTable(newTestSkillsReadme("", "setup", "deploy"))
+
+	testCases := []struct {
+		name         string
+		body         string
+		slugs        []string
+		expectedErrs []string
+	}{
+		{
+			name:  "table is up to date",
+			body:  "# Acme Skills\n\n## Available Skills\n\n" + upToDate,
+			slugs: []string{"setup", "deploy"},
+		},
+		{
+			name:         "missing section",
+			body:         "# Acme Skills\n",
+			slugs:        []string{"setup"},
+			expectedErrs: []string{`README must have an "## Available Skills" section`},
+		},
+		{
+			name:  "missing and undeclared skills",
+			body:  "# Acme Skills\n\n## Available Skills\n\n| Skill | Description |\n| --- | --- |\n| [Setup](https://registry.coder.com/skills/acme/setup) | Does setup. |\n| [Old](https://registry.coder.com/skills/acme/old) | Gone. |\n",
+			slugs: []string{"setup", "deploy"},
+			expectedErrs: []string{
+				`links to "https://registry.coder.com/skills/acme/old", which is not a skill declared`,
+				`table is missing skill "deploy"`,
+			},
+		},
+		{
+			name:         "stale description",
+			body:         "# Acme Skills\n\n## Available Skills\n\n" + strings.ReplaceAll(upToDate, "Does setup. ", "Sets up.    "),
+			slugs:        []string{"setup", "deploy"},
+			expectedErrs: []string{"table is out of date with the frontmatter"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			errs := validateAvailableSkillsTable(newTestSkillsReadme(strings.TrimSpace(tc.body), tc.slugs...))
+			if len(errs) != len(tc.expectedErrs) {
+				t.Fatalf("Expected %d errors, got: %v", len(tc.expectedErrs), errs)
+			}
+			for i, expected := range tc.expectedErrs {
+				if !strings.Contains(errs[i].Error(), expected) {
+					t.Errorf("Expected error containing %q, got: %v", expected, errs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReplaceAvailableSkillsTable(t *testing.T) {
+	t.Parallel()
+
+	rm := newTestSkillsReadme("", "setup")
+	table := generateAvailableSkillsTable(rm)
+
+	stale := "---\nicon: x\n---\n\n# Acme\n\n## Available Skills\n\n| Skill | Description |\n| --- | --- |\n\n## Usage\n\nText.\n"
+	expected := "---\nicon: x\n---\n\n# Acme\n\n## Available Skills\n\n" + table + "\n## Usage\n\nText.\n"
+	if got := replaceAvailableSkillsTable(stale, rm); got != expected {
+		t.Errorf("Unexpected README after replacing table:\n%s", got)
+	}
+
+	missing := "---\nicon: x\n---\n\n# Acme\n"
+	expected = missing + "\n## Available Skills\n\n" + table
+	if got := replaceAvailableSkillsTable(missing, rm); got != expected {
+		t.Errorf("Unexpected README after appending table:\n%s", got)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+)
+
+const contributorStatusPolicyPath = "./.github/contributor-status-policy.yaml"
+
+// contributorStatusRule describes what a namespace with a given contributor status is allowed to do.
+type contributorStatusRule struct {
+	AllowVerified       bool `yaml:"allow_verified"`
+	RequireSupportEmail bool `yaml:"require_support_email"`
+}
+
+// contributorStatusPolicy is the schema of the contributor status policy file.
+type contributorStatusPolicy struct {
+	// Statuses holds the rule for each contributor status.
+	Statuses map[string]contributorStatusRule `yaml:"statuses"`
+	// Exceptions lists the README paths of resources that may stay verified even though their namespace's status
+	// does not allow it.
+	Exceptions []string `yaml:"exceptions"`
+}
+
+func loadContributorStatusPolicy() (contributorStatusPolicy, error) {
+	policy := contributorStatusPolicy{}
+	content, err := os.ReadFile(contributorStatusPolicyPath)
+	if err != nil {
+		return policy, err
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&policy); err != nil {
+		return policy, addFilePathToError(contributorStatusPolicyPath, err)
+	}
+
+	var errs []error
+	for _, status := range validContributorStatuses {
+		if _, ok := policy.Statuses[status]; !ok {
+			errs = append(errs, xerrors.Errorf("%q: missing policy for contributor status %q", contributorStatusPolicyPath, status))
+		}
+	}
+	for status := range policy.Statuses {
+		if !slices.Contains(validContributorStatuses, status) {
+			errs = append(errs, xerrors.Errorf("%q: policy for unknown contributor status %q", contributorStatusPolicyPath, status))
+		}
+	}
+	if len(errs) != 0 {
+		return policy, validationPhaseError{
+			phase:  validationPhaseCrossReference,
+			errors: errs,
+		}
+	}
+	return policy, nil
+}
+
+// resourceNamespace returns the namespace that a module or template README belongs to.
+func resourceNamespace(readmeFilePath string) string {
+	trimmed := strings.TrimPrefix(readmeFilePath, strings.TrimPrefix(rootRegistryPath, "./")+"/")
+	namespace, _, _ := strings.Cut(trimmed, "/")
+	return namespace
+}
+
+// validateContributorStatusPolicy joins every module and template to the contributor profile of its namespace, and
+// checks that both follow the policy for the namespace's contributor status.
+func validateContributorStatusPolicy(policy contributorStatusPolicy, contributors map[string]contributorProfileReadme, resources []coderResourceReadme) []error {
+	var errs []error
+
+	var namespaces []string
+	for namespace := range contributors {
+		namespaces = append(namespaces, namespace)
+	}
+	slices.Sort(namespaces)
+	for _, namespace := range namespaces {
+		con := contributors[namespace]
+		rule := policy.Statuses[con.frontmatter.ContributorStatus]
+		if rule.RequireSupportEmail && con.frontmatter.SupportEmail == nil {
+			errs = append(errs, xerrors.Errorf("%q: namespaces with status %q must set support_email", con.filePath, con.frontmatter.ContributorStatus))
+		}
+	}
+
+	for _, exception := range policy.Exceptions {
+		if !slices.ContainsFunc(resources, func(res coderResourceReadme) bool { return res.filePath == exception }) {
+			errs = append(errs, xerrors.Errorf("%q: exception %q is not the README of any module or template", contributorStatusPolicyPath, exception))
+		}
+	}
+
+	for _, res := range resources {
+		if res.frontmatter.Verified == nil || !*res.frontmatter.Verified {
+			continue
+		}
+		con, ok := contributors[resourceNamespace(res.filePath)]
+		if !ok {
+			// Resources outside of a namespace with a profile are already reported by the repo structure checks.
+			continue
+		}
+		if policy.Statuses[con.frontmatter.ContributorStatus].AllowVerified || slices.Contains(policy.Exceptions, res.filePath) {
+			continue
+		}
+		errs = append(errs, xerrors.Errorf("%q: namespace %q has status %q, which may not mark %s as verified", res.filePath, con.namespace, con.frontmatter.ContributorStatus, res.resourceType))
+	}
+	return errs
+}
+
+// validateAllContributorStatusPolicies runs the contributor status policy checks over the whole registry.
+func validateAllContributorStatusPolicies() error {
+	policy, err := loadContributorStatusPolicy()
+	if err != nil {
+		return err
+	}
+
+	allProfileFiles, err := aggregateContributorReadmeFiles()
+	if err != nil {
+		return err
+	}
+	contributors, err := parseContributorFiles(allProfileFiles)
+	if err != nil {
+		return err
+	}
+
+	var resources []coderResourceReadme
+	for _, resourceType := range supportedResourceTypes {
+		allReadmeFiles, err := aggregateCoderResourceReadmeFiles(resourceType)
+		if err != nil {
+			return err
+		}
+		parsed, err := parseCoderResourceReadmeFiles(resourceType, allReadmeFiles)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, parsed...)
+	}
+
+	if errs := validateContributorStatusPolicy(policy, contributors, resources); len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseCrossReference,
+			errors: errs,
+		}
+	}
+	logger.Info(context.Background(), "all namespaces and resources follow the contributor status policy", "num_resources", len(resources))
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateContributorStatusPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy := contributorStatusPolicy{
+		Statuses: map[string]contributorStatusRule{
+			"official":  {AllowVerified: true},
+			"partner":   {AllowVerified: true, RequireSupportEmail: true},
+			"community": {},
+		},
+	}
+	newContributor := func(namespace, status string, supportEmail *string) contributorProfileReadme {
+		con := newTestContributor(namespace, nil)
+		con.frontmatter.ContributorStatus = status
+		con.frontmatter.SupportEmail = supportEmail
+		return con
+	}
+	newResource := func(filePath string, verified bool) coderResourceReadme {
+		return coderResourceReadme{
+			resourceType: "modules",
+			filePath:     filePath,
+			frontmatter:  coderResourceFrontmatter{Verified: &verified},
+		}
+	}
+	email := "support@example.com"
+
+	testCases := []struct {
+		name         string
+		contributor  contributorProfileReadme
+		resource     coderResourceReadme
+		exceptions   []string
+		expectedErrs []string
+	}{
+		{
+			name:        "official namespace marks resource verified",
+			contributor: newContributor("coder", "official", nil),
+			resource:    newResource("registry/coder/modules/code-server/README.md", true),
+		},
+		{
+			name:        "partner namespace with support email",
+			contributor: newContributor("acme", "partner", &email),
+			resource:    newResource("registry/acme/modules/widget/README.md", true),
+		},
+		{
+			name:         "partner namespace without support email",
+			contributor:  newContributor("acme", "partner", nil),
+			resource:     newResource("registry/acme/modules/widget/README.md", false),
+			expectedErrs: []string{`"registry/acme/README.md": namespaces with status "partner" must set support_email`},
+		},
+		{
+			name:         "community namespace marks resource verified",
+			contributor:  newContributor("jane", "community", nil),
+			resource:     newResource("registry/jane/modules/widget/README.md", true),
+			expectedErrs: []string{`"registry/jane/modules/widget/README.md": namespace "jane" has status "community", which may not mark modules as verified`},
+		},
+		{
+			name:        "community namespace with unverified resource",
+			contributor: newContributor("jane", "community", nil),
+			resource:    newResource("registry/jane/modules/widget/README.md", false),
+		},
+		{
+			name:        "community resource with an exception",
+			contributor: newContributor("harleylrn", "community", nil),
+			resource:    newResource("registry/harleylrn/modules/kiro-cli/README.md", true),
+			exceptions:  []string{"registry/harleylrn/modules/kiro-cli/README.md"},
+		},
+		{
+			name:         "exception for a missing resource",
+			contributor:  newContributor("jane", "community", nil),
+			resource:     newResource("registry/jane/modules/widget/README.md", false),
+			exceptions:   []string{"registry/harleylrn/modules/kiro-cli/README.md"},
+			expectedErrs: []string{`exception "registry/harleylrn/modules/kiro-cli/README.md" is not the README of any module or template`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			contributors := map[string]contributorProfileReadme{tc.contributor.namespace: tc.contributor}
+			policy := policy
+			policy.Exceptions = tc.exceptions
+			errs := validateContributorStatusPolicy(policy, contributors, []coderResourceReadme{tc.resource})
+			if len(errs) != len(tc.expectedErrs) {
+				t.Fatalf("Expected %d errors, got: %v", len(tc.expectedErrs), errs)
+			}
+			for i, expected := range tc.expectedErrs {
+				if !strings.Contains(errs[i].Error(), expected) {
+					t.Errorf("Expected error containing %q, got: %v", expected, errs[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"golang.org/x/xerrors"
+)
+
+// scriptFamily is the kind of shell a script is written for.
+type scriptFamily string
+
+const (
+	scriptFamilyPOSIX      scriptFamily = "a POSIX shell script"
+	scriptFamilyPowerShell scriptFamily = "a PowerShell script"
+)
+
+// powerShellSyntaxRe matches lines that only make sense in PowerShell, for scripts that have neither a shebang nor a
+// telling file extension (like .tftpl templates).
+var powerShellSyntaxRe = regexp.MustCompile(`(?m)^\s*(\$[A-Za-z_]\w*\s*=|function\s+[A-Z]\w*-[A-Z]\w*|(Add|Get|Install|Invoke|New|Remove|Set|Start|Write)-[A-Z]\w+)`)
+
+// classifyScript returns the shell family of a script from its shebang, its file extension (ignoring template
+// extensions), or failing both, its syntax. It returns an empty string for files that aren't recognizably scripts.
+func classifyScript(fileName string, content string) scriptFamily {
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(content), "\n")
+	if strings.HasPrefix(firstLine, "#!") {
+		if strings.Contains(firstLine, "pwsh") || strings.Contains(firstLine, "powershell") {
+			return scriptFamilyPowerShell
+		}
+		return scriptFamilyPOSIX
+	}
+
+	name := strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(fileName), ".tftpl"), ".tpl")
+	switch path.Ext(name) {
+	case ".sh", ".bash", ".zsh":
+		return scriptFamilyPOSIX
+	case ".ps1", ".psm1":
+		return scriptFamilyPowerShell
+	}
+	if powerShellSyntaxRe.MatchString(content) {
+		return scriptFamilyPowerShell
+	}
+	return ""
+}
+
+// agentOperatingSystems maps the values of the coder_agent os attribute to the names used in supported_os.
+var agentOperatingSystems = map[string]string{
+	"linux":   "linux",
+	"darwin":  "macos",
+	"macos":   "macos",
+	"windows": "windows",
+}
+
+// osSupport is what the Terraform of a module or template reveals about the operating systems it supports. Both maps
+// go from an operating system to the code that shows it, described for error messages.
+type osSupport struct {
+	// compatible lists every operating system the code can run on.
+	compatible map[string][]string
+	// targeted lists the operating systems the code was explicitly written for: agents that run them, scripts in their
+	// native shell, and conditionals that check for them. It is a subset of compatible, because a POSIX shell script
+	// runs on macOS, but that doesn't mean anyone made sure that it works there.
+	targeted map[string][]string
+}
+
+func (s osSupport) add(osName string, evidence string, targeted bool) {
+	if !slices.Contains(s.compatible[osName], evidence) {
+		s.compatible[osName] = append(s.compatible[osName], evidence)
+	}
+	if targeted && !slices.Contains(s.targeted[osName], evidence) {
+		s.targeted[osName] = append(s.targeted[osName], evidence)
+	}
+}
+
+func (s osSupport) addScript(family scriptFamily, evidence string) {
+	switch family {
+	case scriptFamilyPOSIX:
+		s.add("linux", evidence, true)
+		s.add("macos", evidence, false)
+	case scriptFamilyPowerShell:
+		s.add("windows", evidence, true)
+	}
+}
+
+// operatingSystems returns the operating systems of one of the maps of s, in the order of operatingSystems.
+func (osSupport) operatingSystems(evidence map[string][]string) []string {
+	var found []string
+	for _, osName := range operatingSystems {
+		if len(evidence[osName]) != 0 {
+			found = append(found, osName)
+		}
+	}
+	return found
+}
+
+// osSupportInference walks the Terraform configuration of a module or template to infer its osSupport.
+type osSupportInference struct {
+	tf      coderResourceTerraform
+	locals  map[string]*hclsyntax.Attribute
+	support osSupport
+}
+
+// inferOSSupport infers the operating systems a module or template supports from the scripts that its Terraform reads
+// with file and templatefile, the inline scripts of its coder_script resources, the os of its coder_agent resources,
+// and any conditionals that compare something named os to an operating system.
+func inferOSSupport(tf coderResourceTerraform) osSupport {
+	inf := osSupportInference{
+		tf:      tf,
+		locals:  tf.locals(),
+		support: osSupport{compatible: map[string][]string{}, targeted: map[string][]string{}},
+	}
+
+	for _, b := range tf.blocks("resource", "coder_agent") {
+		attr, ok := b.block.Body.Attributes["os"]
+		if !ok || len(b.block.Labels) != 2 {
+			continue
+		}
+		value, ok := literalString(attr.Expr)
+		if osName, known := agentOperatingSystems[value]; ok && known {
+			inf.support.add(osName, fmt.Sprintf("coder_agent.%s runs on %s", b.block.Labels[1], value), true)
+		}
+	}
+	for _, b := range tf.blocks("resource", "coder_script") {
+		if attr, ok := b.block.Body.Attributes["script"]; ok && len(b.block.Labels) == 2 {
+			inf.inlineScript(attr.Expr, "coder_script."+b.block.Labels[1], 0)
+		}
+	}
+	for _, filePath := range tf.readFilePaths() {
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			continue
+		}
+		family := classifyScript(filePath, string(content))
+		inf.support.addScript(family, fmt.Sprintf("%s is %s", strings.TrimPrefix(filePath, tf.dirPath+"/"), family))
+	}
+	for _, f := range tf.files {
+		_ = hclsyntax.VisitAll(f.body, func(node hclsyntax.Node) hcl.Diagnostics {
+			if e, ok := node.(*hclsyntax.BinaryOpExpr); ok {
+				inf.osConditional(e)
+			}
+			return nil
+		})
+	}
+	return inf.support
+}
+
+// maxLocalDepth bounds how many locals are followed to find an inline script, in case they reference each other.
+const maxLocalDepth = 8
+
+// inlineScript classifies a script written directly in a coder_script resource, following conditionals and locals.
+// Scripts read from files are picked up through readFilePaths.
+func (inf osSupportInference) inlineScript(expr hclsyntax.Expression, resource string, depth int) {
+	switch e := expr.(type) {
+	case *hclsyntax.ConditionalExpr:
+		inf.inlineScript(e.TrueResult, resource, depth)
+		inf.inlineScript(e.FalseResult, resource, depth)
+	case *hclsyntax.ScopeTraversalExpr:
+		names := referencedNames(e, "local")
+		if len(names) == 0 || depth >= maxLocalDepth {
+			return
+		}
+		if attr, ok := inf.locals[names[0]]; ok {
+			inf.inlineScript(attr.Expr, resource, depth+1)
+		}
+	case *hclsyntax.TemplateExpr:
+		idx := slices.IndexFunc(inf.tf.files, func(f terraformFile) bool { return f.filePath == e.Range().Filename })
+		if idx == -1 {
+			return
+		}
+		src := inf.tf.files[idx].expressionSource(e)
+		if strings.HasPrefix(src, "<<") {
+			_, src, _ = strings.Cut(src, "\n")
+		}
+		family := classifyScript("", strings.TrimPrefix(src, `"`))
+		inf.support.addScript(family, fmt.Sprintf("the inline script of %s is %s", resource, family))
+	}
+}
+
+// osConditional records the operating system in comparisons like `data.coder_provisioner.me.os == "windows"`.
+func (inf osSupportInference) osConditional(e *hclsyntax.BinaryOpExpr) {
+	if e.Op != hclsyntax.OpEqual && e.Op != hclsyntax.OpNotEqual {
+		return
+	}
+	for _, pair := range [][2]hclsyntax.Expression{{e.LHS, e.RHS}, {e.RHS, e.LHS}} {
+		value, ok := literalString(pair[0])
+		osName, known := agentOperatingSystems[strings.ToLower(value)]
+		if !ok || !known || !referencesOS(pair[1]) {
+			continue
+		}
+		evidence := fmt.Sprintf("%s:%d checks for %s", path.Base(e.Range().Filename), e.Range().Start.Line, value)
+		inf.support.add(osName, evidence, true)
+	}
+}
+
+// referencesOS reports whether an expression references anything named os, like var.os or
+// data.coder_provisioner.me.os.
+func referencesOS(expr hclsyntax.Expression) bool {
+	for _, traversal := range expr.Variables() {
+		for _, step := range traversal[1:] {
+			if attr, ok := step.(hcl.TraverseAttr); ok && (attr.Name == "os" || strings.HasSuffix(attr.Name, "_os")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateSupportedOS compares the supported_os declared in a README with what the Terraform code supports. Declaring
+// an operating system the code can't run on is an error, while leaving out one the code was written for is only a
+// warning. Resources that don't declare supported_os make no claim, and are only warned about when their code can't
+// run on Linux, which is what workspaces are assumed to run.
+func validateSupportedOS(declared []string, support osSupport) (errs []error, warnings []error) {
+	compatible := support.operatingSystems(support.compatible)
+	if len(compatible) == 0 {
+		return nil, nil
+	}
+
+	if len(declared) == 0 {
+		if !slices.Contains(compatible, "linux") {
+			warnings = append(warnings, xerrors.Errorf("supported_os is not declared, but the code only runs on %s (%s); declare \"supported_os: [%s]\"",
+				strings.Join(compatible, ", "), explainOSSupport(support.compatible, compatible), strings.Join(compatible, ", ")))
+		}
+		return nil, warnings
+	}
+
+	for _, osName := range declared {
+		if !slices.Contains(compatible, osName) {
+			errs = append(errs, xerrors.Errorf("supported_os includes %q, but the code only runs on %s (%s)",
+				osName, strings.Join(compatible, ", "), explainOSSupport(support.compatible, compatible)))
+		}
+	}
+	for _, osName := range support.operatingSystems(support.targeted) {
+		if !slices.Contains(declared, osName) {
+			warnings = append(warnings, xerrors.Errorf("supported_os does not include %q, but the code supports it (%s)",
+				osName, explainOSSupport(support.targeted, []string{osName})))
+		}
+	}
+	return errs, warnings
+}
+
+// explainOSSupport joins the evidence for the given operating systems into a single description.
+func explainOSSupport(evidence map[string][]string, osNames []string) string {
+	var reasons []string
+	for _, osName := range osNames {
+		for _, reason := range evidence[osName] {
+			if !slices.Contains(reasons, reason) {
+				reasons = append(reasons, reason)
+			}
+		}
+	}
+	slices.Sort(reasons)
+	return strings.Join(reasons, "; ")
+}
+
+// validateAllSupportedOS checks the supported_os of every module and template against what its code supports.
+func validateAllSupportedOS() error {
+	catalog, err := loadCatalog()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, r := range catalog {
+		osErrs, warnings := validateSupportedOS(r.readme.frontmatter.OperatingSystems, inferOSSupport(r.terraform))
+		for _, err := range osErrs {
+			errs = append(errs, addFilePathToError(r.readme.filePath, err))
+		}
+		for _, w := range warnings {
+			logger.Warn(context.Background(), addFilePathToError(r.readme.filePath, w).Error())
+		}
+	}
+	if len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseTerraform,
+			errors: errs,
+		}
+	}
+	logger.Info(context.Background(), "checked supported_os against the Terraform of every module and template", "num_resources", len(catalog))
+	return nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestClassifyScript(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		fileName string
+		content  string
+		expected scriptFamily
+	}{
+		{name: "bash shebang", fileName: "run.sh", content: "#!/usr/bin/env bash\necho hi\n", expected: scriptFamilyPOSIX},
+		{name: "shebang wins over extension", fileName: "install.tftpl", content: "\n#!/bin/sh\necho hi\n", expected: scriptFamilyPOSIX},
+		{name: "pwsh shebang", fileName: "run.sh", content: "#!/usr/bin/env pwsh\nWrite-Output hi\n", expected: scriptFamilyPowerShell},
+		{name: "shell template", fileName: "scripts/start.sh.tftpl", content: "echo ${greeting}\n", expected: scriptFamilyPOSIX},
+		{name: "PowerShell extension", fileName: "install.ps1", content: "# Install things\n", expected: scriptFamilyPowerShell},
+		{name: "PowerShell syntax", fileName: "install.tftpl", content: "function Set-AdminPassword {\n  param ([string]$adminPassword)\n}\n", expected: scriptFamilyPowerShell},
+		{name: "not a script", fileName: "patch.js", content: "const x = 1;\n", expected: ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := classifyScript(tc.fileName, tc.content); got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestInferOSSupport(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		terraform          string
+		files              map[string]string
+		expectedCompatible []string
+		expectedTargeted   []string
+	}{
+		{
+			name:      "no scripts",
+			terraform: `resource "coder_app" "app" {}`,
+		},
+		{
+			name:               "shell script file",
+			terraform:          `resource "coder_script" "run" { script = templatefile("${path.module}/run.sh", {}) }`,
+			files:              map[string]string{"run.sh": "#!/usr/bin/env bash\n"},
+			expectedCompatible: []string{"macos", "linux"},
+			expectedTargeted:   []string{"linux"},
+		},
+		{
+			name: "PowerShell script through a local",
+			terraform: `
+locals {
+  script = file("${path.module}/install.ps1")
+}
+resource "coder_script" "run" { script = local.script }`,
+			files:              map[string]string{"install.ps1": "Write-Output hi\n"},
+			expectedCompatible: []string{"windows"},
+			expectedTargeted:   []string{"windows"},
+		},
+		{
+			name: "inline heredoc script",
+			terraform: `
+resource "coder_script" "run" {
+  script = <<-EOT
+    #!/bin/bash
+    echo ${var.greeting}
+  EOT
+}`,
+			expectedCompatible: []string{"macos", "linux"},
+			expectedTargeted:   []string{"linux"},
+		},
+		{
+			name: "os-conditional script",
+			terraform: `
+data "coder_provisioner" "me" {}
+resource "coder_script" "run" {
+  script = data.coder_provisioner.me.os == "windows" ? file("${path.module}/run.ps1") : file("${path.module}/run.sh")
+}`,
+			files:              map[string]string{"run.ps1": "Write-Output hi\n", "run.sh": "echo hi\n"},
+			expectedCompatible: []string{"windows", "macos", "linux"},
+			expectedTargeted:   []string{"windows", "linux"},
+		},
+		{
+			name:               "agent os",
+			terraform:          `resource "coder_agent" "main" { os = "darwin" }`,
+			expectedCompatible: []string{"macos"},
+			expectedTargeted:   []string{"macos"},
+		},
+		{
+			name: "resources without a name are ignored",
+			terraform: `
+resource "coder_agent" { os = "linux" }
+resource "coder_script" { script = "#!/bin/bash" }`,
+		},
+		{
+			name:      "missing and non-literal files are ignored",
+			terraform: `resource "coder_script" "run" { script = file(var.script_path) != "" ? file("${path.module}/missing.sh") : "" }`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				if err := os.WriteFile(path.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			tf := parseTestTerraform(t, tc.terraform)
+			tf.dirPath = dir
+
+			support := inferOSSupport(tf)
+			if got := support.operatingSystems(support.compatible); !slices.Equal(got, tc.expectedCompatible) {
+				t.Errorf("expected compatible operating systems %v, got %v", tc.expectedCompatible, got)
+			}
+			if got := support.operatingSystems(support.targeted); !slices.Equal(got, tc.expectedTargeted) {
+				t.Errorf("expected targeted operating systems %v, got %v", tc.expectedTargeted, got)
+			}
+		})
+	}
+}
+
+func TestValidateSupportedOS(t *testing.T) {
+	t.Parallel()
+
+	posix := osSupport{compatible: map[string][]string{}, targeted: map[string][]string{}}
+	posix.addScript(scriptFamilyPOSIX, "run.sh is a POSIX shell script")
+	windows := osSupport{compatible: map[string][]string{}, targeted: map[string][]string{}}
+	windows.addScript(scriptFamilyPowerShell, "run.ps1 is a PowerShell script")
+	both := osSupport{compatible: map[string][]string{}, targeted: map[string][]string{}}
+	both.addScript(scriptFamilyPOSIX, "run.sh is a POSIX shell script")
+	both.add("windows", "main.tf:3 checks for windows", true)
+
+	testCases := []struct {
+		name             string
+		declared         []string
+		support          osSupport
+		expectedErrs     []string
+		expectedWarnings []string
+	}{
+		{
+			name:     "no evidence",
+			declared: []string{"windows"},
+			support:  osSupport{},
+		},
+		{
+			name:    "undeclared POSIX module",
+			support: posix,
+		},
+		{
+			name:     "matching declaration",
+			declared: []string{"linux", "macos"},
+			support:  posix,
+		},
+		{
+			name:         "contradiction",
+			declared:     []string{"linux", "windows"},
+			support:      posix,
+			expectedErrs: []string{`supported_os includes "windows", but the code only runs on macos, linux (run.sh is a POSIX shell script)`},
+		},
+		{
+			name:             "omission",
+			declared:         []string{"linux"},
+			support:          both,
+			expectedWarnings: []string{`supported_os does not include "windows", but the code supports it (main.tf:3 checks for windows)`},
+		},
+		{
+			name:             "undeclared Windows module",
+			support:          windows,
+			expectedWarnings: []string{`supported_os is not declared, but the code only runs on windows (run.ps1 is a PowerShell script); declare "supported_os: [windows]"`},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			errs, warnings := validateSupportedOS(tc.declared, tc.support)
+			for _, check := range []struct {
+				kind     string
+				got      []error
+				expected []string
+			}{
+				{"errors", errs, tc.expectedErrs},
+				{"warnings", warnings, tc.expectedWarnings},
+			} {
+				if len(check.got) != len(check.expected) {
+					t.Fatalf("expected %d %s, got: %v", len(check.expected), check.kind, check.got)
+				}
+				for i, expected := range check.expected {
+					if !strings.Contains(check.got[i].Error(), expected) {
+						t.Errorf("expected %s containing %q, got: %v", check.kind, expected, check.got[i])
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/agext/levenshtein"
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+)
+
+const tagVocabularyPath = "./.github/tag-vocabulary.yaml"
+
+// deprecatedTag describes a tag that must no longer be used.
+type deprecatedTag struct {
+	Reason      string `yaml:"reason"`
+	Replacement string `yaml:"replacement"`
+}
+
+// tagVocabulary is the schema of the tag vocabulary file.
+type tagVocabulary struct {
+	// Tags maps every canonical tag to its synonyms.
+	Tags       map[string][]string      `yaml:"tags"`
+	Deprecated map[string]deprecatedTag `yaml:"deprecated"`
+}
+
+// synonyms returns a map from every synonym in the vocabulary to its canonical tag.
+func (v tagVocabulary) synonyms() map[string]string {
+	synonyms := map[string]string{}
+	for canonical, tagSynonyms := range v.Tags {
+		for _, synonym := range tagSynonyms {
+			synonyms[synonym] = canonical
+		}
+	}
+	return synonyms
+}
+
+// suggest returns the canonical tag closest to an unknown tag, matching its normalized form against both canonical
+// tags and synonyms. It returns an empty string when nothing is close enough to be a likely typo.
+func (v tagVocabulary) suggest(tag string) string {
+	tag = normalizeTag(tag)
+	synonyms := v.synonyms()
+	candidates := make([]string, 0, len(v.Tags)+len(synonyms))
+	for canonical := range v.Tags {
+		candidates = append(candidates, canonical)
+	}
+	for synonym := range synonyms {
+		candidates = append(candidates, synonym)
+	}
+	slices.Sort(candidates)
+
+	best := ""
+	bestDistance := max(1, len(tag)/3) + 1
+	for _, candidate := range candidates {
+		if d := levenshtein.Distance(tag, candidate, nil); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	if canonical, ok := synonyms[best]; ok {
+		return canonical
+	}
+	return best
+}
+
+func loadTagVocabulary() (tagVocabulary, error) {
+	vocab := tagVocabulary{}
+	content, err := os.ReadFile(tagVocabularyPath)
+	if err != nil {
+		return vocab, err
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&vocab); err != nil {
+		return vocab, addFilePathToError(tagVocabularyPath, err)
+	}
+
+	var errs []error
+	claimedBy := map[string]string{}
+	for canonical, synonyms := range vocab.Tags {
+		for _, synonym := range synonyms {
+			if _, ok := vocab.Tags[synonym]; ok {
+				errs = append(errs, xerrors.Errorf("synonym %q of tag %q is also a canonical tag", synonym, canonical))
+			}
+			if prev, ok := claimedBy[synonym]; ok {
+				errs = append(errs, xerrors.Errorf("synonym %q is claimed by both %q and %q", synonym, prev, canonical))
+			}
+			claimedBy[synonym] = canonical
+		}
+	}
+	for tag, dep := range vocab.Deprecated {
+		if _, ok := vocab.Tags[tag]; ok {
+			errs = append(errs, xerrors.Errorf("deprecated tag %q is also a canonical tag", tag))
+		}
+		if _, ok := claimedBy[tag]; ok {
+			errs = append(errs, xerrors.Errorf("deprecated tag %q is also a synonym", tag))
+		}
+		if _, ok := vocab.Tags[dep.Replacement]; dep.Replacement != "" && !ok {
+			errs = append(errs, xerrors.Errorf("replacement %q for deprecated tag %q is not a canonical tag", dep.Replacement, tag))
+		}
+	}
+	for tag := range vocab.Tags {
+		if normalizeTag(tag) != tag {
+			errs = append(errs, xerrors.Errorf("tag %q is not normalized (expected %q)", tag, normalizeTag(tag)))
+		}
+	}
+	if len(errs) != 0 {
+		for i, err := range errs {
+			errs[i] = addFilePathToError(tagVocabularyPath, err)
+		}
+		slices.SortFunc(errs, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+		return vocab, validationPhaseError{
+			phase:  validationPhaseCrossReference,
+			errors: errs,
+		}
+	}
+	return vocab, nil
+}
+
+// validateTagsAgainstVocabulary checks a list of tags against the vocabulary. Tags are looked up in their normalized
+// form, so that "IDE" is recognized as "ide". Deprecated tags are errors, while tags that aren't normalized, synonyms,
+// and unknown tags are only warnings, so that new tags can be proposed alongside the resources that use them.
+func validateTagsAgainstVocabulary(vocab tagVocabulary, tags []string) (errs []error, warnings []error) {
+	synonyms := vocab.synonyms()
+	for _, tag := range tags {
+		normalized := normalizeTag(tag)
+		if normalized != tag {
+			warnings = append(warnings, xerrors.Errorf("tag %q is not normalized (expected %q); run \"./readmevalidation fmt\" to fix it", tag, normalized))
+		}
+		if _, ok := vocab.Tags[normalized]; ok {
+			continue
+		}
+		if dep, ok := vocab.Deprecated[normalized]; ok {
+			msg := fmt.Sprintf("tag %q is deprecated", tag)
+			if dep.Reason != "" {
+				msg += ": " + dep.Reason
+			}
+			if dep.Replacement != "" {
+				msg += fmt.Sprintf(" (use %q instead)", dep.Replacement)
+			}
+			errs = append(errs, xerrors.New(msg))
+			continue
+		}
+		if canonical, ok := synonyms[normalized]; ok {
+			warnings = append(warnings, xerrors.Errorf("tag %q is a synonym of %q; run \"./readmevalidation fmt\" to replace it", tag, canonical))
+			continue
+		}
+		if suggestion := vocab.suggest(tag); suggestion != "" {
+			warnings = append(warnings, xerrors.Errorf("tag %q is not in the tag vocabulary (did you mean %q?); add it to %q if it is a new tag", tag, suggestion, tagVocabularyPath))
+			continue
+		}
+		warnings = append(warnings, xerrors.Errorf("tag %q is not in the tag vocabulary; add it to %q if it is a new tag", tag, tagVocabularyPath))
+	}
+	return errs, warnings
+}
+
+// validateAllTagVocabulary checks the tags of every module, template, and skill against the tag vocabulary.
+func validateAllTagVocabulary() error {
+	vocab, err := loadTagVocabulary()
+	if err != nil {
+		return err
+	}
+
+	tagsByPath := map[string][]string{}
+	for _, resourceType := range supportedResourceTypes {
+		allReadmeFiles, err := aggregateCoderResourceReadmeFiles(resourceType)
+		if err != nil {
+			return err
+		}
+		readmes, err := parseCoderResourceReadmeFiles(resourceType, allReadmeFiles)
+		if err != nil {
+			return err
+		}
+		for _, rm := range readmes {
+			tagsByPath[rm.filePath] = rm.frontmatter.Tags
+		}
+	}
+	allSkillsReadmes, err := aggregateSkillsReadmeFiles()
+	if err != nil {
+		return err
+	}
+	skillsReadmes, err := parseCoderSkillsReadmeFiles(allSkillsReadmes)
+	if err != nil {
+		return err
+	}
+	for _, rm := range skillsReadmes {
+		for _, src := range rm.frontmatter.Sources {
+			for _, override := range src.Skills {
+				tagsByPath[rm.filePath] = append(tagsByPath[rm.filePath], override.Tags...)
+			}
+		}
+	}
+
+	var paths []string
+	for filePath := range tagsByPath {
+		paths = append(paths, filePath)
+	}
+	slices.Sort(paths)
+
+	var errs []error
+	for _, filePath := range paths {
+		tags := slices.Compact(slices.Sorted(slices.Values(tagsByPath[filePath])))
+		tagErrs, warnings := validateTagsAgainstVocabulary(vocab, tags)
+		for _, err := range tagErrs {
+			errs = append(errs, addFilePathToError(filePath, err))
+		}
+		for _, w := range warnings {
+			logger.Warn(context.Background(), addFilePathToError(filePath, w).Error())
+		}
+	}
+	if len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseCrossReference,
+			errors: errs,
+		}
+	}
+	logger.Info(context.Background(), "checked all tags against the tag vocabulary", "num_tags", len(vocab.Tags))
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateTagsAgainstVocabulary(t *testing.T) {
+	t.Parallel()
+
+	vocab := tagVocabulary{
+		Tags: map[string][]string{
+			"ide":          {"editor"},
+			"docker":       nil,
+			"agent":        {"agents", "ai-agent"},
+			"vm":           nil,
+			"devcontainer": {"devcontainers"},
+		},
+		Deprecated: map[string]deprecatedTag{
+			"development": {Reason: "Too broad."},
+			"helper":      {Replacement: "agent"},
+		},
+	}
+
+	testCases := []struct {
+		name             string
+		tags             []string
+		expectedErrs     []string
+		expectedWarnings []string
+	}{
+		{
+			name: "canonical tags",
+			tags: []string{"ide", "docker"},
+		},
+		{
+			name:         "deprecated tags",
+			tags:         []string{"development", "helper"},
+			expectedErrs: []string{`tag "development" is deprecated: Too broad.`, `tag "helper" is deprecated (use "agent" instead)`},
+		},
+		{
+			name:             "synonyms",
+			tags:             []string{"editor"},
+			expectedWarnings: []string{`tag "editor" is a synonym of "ide"`},
+		},
+		{
+			name: "unknown tags",
+			tags: []string{"dokcer", "ai-agnet", "kubernetes"},
+			expectedWarnings: []string{
+				`tag "dokcer" is not in the tag vocabulary (did you mean "docker"?)`,
+				`tag "ai-agnet" is not in the tag vocabulary (did you mean "agent"?)`,
+				`tag "kubernetes" is not in the tag vocabulary; add it to`,
+			},
+		},
+		{
+			name:             "tag that is not normalized",
+			tags:             []string{"IDE"},
+			expectedWarnings: []string{`tag "IDE" is not normalized (expected "ide"); run "./readmevalidation fmt" to fix it`},
+		},
+		{
+			name: "unknown tag that is not normalized",
+			tags: []string{"Dev Containers"},
+			expectedWarnings: []string{
+				`tag "Dev Containers" is not normalized (expected "dev-containers")`,
+				`tag "Dev Containers" is not in the tag vocabulary (did you mean "devcontainer"?)`,
+			},
+		},
+		{
+			name:             "synonym that is not normalized",
+			tags:             []string{"Editor"},
+			expectedWarnings: []string{`tag "Editor" is not normalized (expected "editor")`, `tag "Editor" is a synonym of "ide"`},
+		},
+		{
+			name:             "deprecated tag that is not normalized",
+			tags:             []string{"Helper"},
+			expectedErrs:     []string{`tag "Helper" is deprecated (use "agent" instead)`},
+			expectedWarnings: []string{`tag "Helper" is not normalized (expected "helper")`},
+		},
+		{
+			name:             "short tags need a close match",
+			tags:             []string{"vn", "ai"},
+			expectedWarnings: []string{`(did you mean "vm"?)`, `tag "ai" is not in the tag vocabulary; add it to`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			errs, warnings := validateTagsAgainstVocabulary(vocab, tc.tags)
+			for _, check := range []struct {
+				kind     string
+				got      []error
+				expected []string
+			}{
+				{"errors", errs, tc.expectedErrs},
+				{"warnings", warnings, tc.expectedWarnings},
+			} {
+				if len(check.got) != len(check.expected) {
+					t.Fatalf("expected %d %s, got: %v", len(check.expected), check.kind, check.got)
+				}
+				for i, expected := range check.expected {
+					if !strings.Contains(check.got[i].Error(), expected) {
+						t.Errorf("expected %s containing %q, got: %v", check.kind, expected, check.got[i])
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// registryModuleSourcePrefix is the host prefix of every module source served by this registry. The full source has
+// the form "registry.coder.com/<namespace>/<module>/coder".
+const registryModuleSourcePrefix = "registry.coder.com/"
+
+// moduleMetaArguments are the arguments that Terraform accepts on every module block. They are never declared as
+// variables by the module itself.
+var moduleMetaArguments = []string{"source", "version", "count", "for_each", "depends_on", "providers"}
+
+// parseRegistryModuleSource splits a registry module source into its namespace and module name.
+func parseRegistryModuleSource(source string) (namespace string, moduleName string, err error) {
+	trimmed, ok := strings.CutPrefix(source, registryModuleSourcePrefix)
+	if !ok {
+		return "", "", xerrors.Errorf("module source %q is not a %s source", source, registryModuleSourcePrefix)
+	}
+	segments := strings.Split(trimmed, "/")
+	if len(segments) != 3 || segments[0] == "" || segments[1] == "" || segments[2] != "coder" {
+		return "", "", xerrors.Errorf("module source %q must have the form %q", source, registryModuleSourcePrefix+"<namespace>/<module>/coder")
+	}
+	return segments[0], segments[1], nil
+}
+
+// validateTemplateModuleReferences checks every registry module referenced by a template resolves to a module in
+// this repo, that its pinned version matches a release, and that every argument passed to it is a variable declared
+// by that release. Templates in this repo must use the current path of a module, not an old path from the alias file.
+// When the pinned release can't be inspected because the repo has no release tags, arguments are checked against the
+// module's current variables instead, and mismatches are returned as warnings, because the older release may still
+// have declared them.
+func validateTemplateModuleReferences(templates []coderResourceTerraform, releases moduleReleaseIndex, aliases registryAliases) (errs []error, warnings []error) {
+	type cacheKey struct {
+		module string
+		tag    string
+	}
+	variablesCache := map[cacheKey][]string{}
+
+	for _, tf := range templates {
+		for _, mb := range tf.blocks("module") {
+			sourceAttr, ok := mb.block.Body.Attributes["source"]
+			if !ok || len(mb.block.Labels) != 1 {
+				continue
+			}
+			source, isLiteral := literalString(sourceAttr.Expr)
+			if !isLiteral || !strings.HasPrefix(source, registryModuleSourcePrefix) {
+				continue
+			}
+
+			namespace, moduleName, err := parseRegistryModuleSource(source)
+			if err != nil {
+				errs = append(errs, addRangeToError(sourceAttr.SrcRange, err))
+				continue
+			}
+			if target, aliased := aliases.resolveModule(namespace + "/" + moduleName); aliased {
+				errs = append(errs, addRangeToError(sourceAttr.SrcRange, xerrors.Errorf("module source %q has moved; use %q", source, registryModuleSourcePrefix+target+"/coder")))
+				continue
+			}
+			moduleDir := path.Join(rootRegistryPath, namespace, "modules", moduleName)
+			if _, err := os.Stat(path.Join(moduleDir, "main.tf")); err != nil {
+				errs = append(errs, addRangeToError(sourceAttr.SrcRange, xerrors.Errorf("module source %q does not match any module in this registry", source)))
+				continue
+			}
+
+			versionAttr, ok := mb.block.Body.Attributes["version"]
+			if !ok {
+				errs = append(errs, addRangeToError(mb.block.DefRange(), xerrors.Errorf("module %q must pin a version", mb.block.Labels[0])))
+				continue
+			}
+			constraint, isLiteral := literalString(versionAttr.Expr)
+			if !isLiteral {
+				errs = append(errs, addRangeToError(versionAttr.SrcRange, xerrors.New("module version must be a literal string")))
+				continue
+			}
+			release, err := releases.resolve(namespace+"/"+moduleName, constraint)
+			if err != nil {
+				errs = append(errs, addRangeToError(versionAttr.SrcRange, err))
+				continue
+			}
+			key := cacheKey{module: moduleDir, tag: release.tag}
+			variables, cached := variablesCache[key]
+			if !cached {
+				variables, err = moduleVariablesAtRelease(moduleDir, release)
+				if err != nil {
+					errs = append(errs, addRangeToError(sourceAttr.SrcRange, err))
+					continue
+				}
+				variablesCache[key] = variables
+			}
+
+			// Attributes are stored in a map, so sort them to keep the output stable between runs.
+			var argNames []string
+			for name := range mb.block.Body.Attributes {
+				argNames = append(argNames, name)
+			}
+			slices.Sort(argNames)
+			for _, name := range argNames {
+				if slices.Contains(moduleMetaArguments, name) || slices.Contains(variables, name) {
+					continue
+				}
+				attr := mb.block.Body.Attributes[name]
+				if release.unverified {
+					current := releases.releases[namespace+"/"+moduleName][0].version
+					warnings = append(warnings, addRangeToError(attr.SrcRange, xerrors.Errorf("argument %q is not a variable of module %q at its current version %s; version %s could not be checked without release tags", name, namespace+"/"+moduleName, current, release.version)))
+					continue
+				}
+				errs = append(errs, addRangeToError(attr.SrcRange, xerrors.Errorf("argument %q is not a variable of module %q at version %s", name, namespace+"/"+moduleName, release.version)))
+			}
+		}
+	}
+	return errs, warnings
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	goversion "github.com/hashicorp/go-version"
+)
+
+func TestParseRegistryModuleSource(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		source     string
+		namespace  string
+		moduleName string
+		shouldPass bool
+	}{
+		{source: "registry.coder.com/coder/code-server/coder", namespace: "coder", moduleName: "code-server", shouldPass: true},
+		{source: "registry.coder.com/coder-labs/codex/coder", namespace: "coder-labs", moduleName: "codex", shouldPass: true},
+		{source: "registry.coder.com/coder/code-server", shouldPass: false},
+		{source: "registry.coder.com/coder/code-server/aws", shouldPass: false},
+		{source: "registry.coder.com//code-server/coder", shouldPass: false},
+		{source: "terraform-google-modules/container-vm/google", shouldPass: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.source, func(t *testing.T) {
+			t.Parallel()
+
+			namespace, moduleName, err := parseRegistryModuleSource(tc.source)
+			if !tc.shouldPass {
+				if err == nil {
+					t.Errorf("expected %q to be rejected, got %q/%q", tc.source, namespace, moduleName)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if namespace != tc.namespace || moduleName != tc.moduleName {
+				t.Errorf("expected %q/%q, got %q/%q", tc.namespace, tc.moduleName, namespace, moduleName)
+			}
+		})
+	}
+}
+
+func TestModuleReleaseIndexResolve(t *testing.T) {
+	t.Parallel()
+
+	tagged := moduleReleaseIndex{
+		fromTags: true,
+		releases: map[string][]moduleRelease{
+			"coder/code-server": {
+				{version: goversion.Must(goversion.NewVersion("1.0.0")), tag: "release/coder/code-server/v1.0.0"},
+				{version: goversion.Must(goversion.NewVersion("1.3.1")), tag: "release/coder/code-server/v1.3.1"},
+				{version: goversion.Must(goversion.NewVersion("2.0.0")), tag: "release/coder/code-server/v2.0.0"},
+			},
+		},
+	}
+	untagged := moduleReleaseIndex{
+		releases: map[string][]moduleRelease{
+			"coder/code-server": {{version: goversion.Must(goversion.NewVersion("1.5.2"))}},
+		},
+	}
+
+	testCases := []struct {
+		name       string
+		index      moduleReleaseIndex
+		module     string
+		constraint string
+		expected   string
+		unverified bool
+		shouldPass bool
+	}{
+		{name: "pessimistic picks newest match", index: tagged, module: "coder/code-server", constraint: "~> 1.0", expected: "1.3.1", shouldPass: true},
+		{name: "exact pin", index: tagged, module: "coder/code-server", constraint: "1.0.0", expected: "1.0.0", shouldPass: true},
+		{name: "unreleased exact pin", index: tagged, module: "coder/code-server", constraint: "1.2.0", shouldPass: false},
+		{name: "unknown module", index: tagged, module: "coder/missing", constraint: "~> 1.0", shouldPass: false},
+		{name: "invalid constraint", index: tagged, module: "coder/code-server", constraint: "latest", shouldPass: false},
+		{name: "untagged current version", index: untagged, module: "coder/code-server", constraint: "~> 1.0", expected: "1.5.2", shouldPass: true},
+		{name: "untagged older pin", index: untagged, module: "coder/code-server", constraint: "1.3.1", expected: "1.3.1", unverified: true, shouldPass: true},
+		{name: "untagged future pin", index: untagged, module: "coder/code-server", constraint: "~> 2.0", shouldPass: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			release, err := tc.index.resolve(tc.module, tc.constraint)
+			if !tc.shouldPass {
+				if err == nil {
+					t.Errorf("expected %q to not resolve, got %s", tc.constraint, release.version)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if release.version.String() != tc.expected {
+				t.Errorf("expected version %s, got %s", tc.expected, release.version)
+			}
+			if release.unverified != tc.unverified {
+				t.Errorf("expected unverified to be %t, got %t", tc.unverified, release.unverified)
+			}
+		})
+	}
+}
+
+func TestValidateTemplateModuleReferencesUntagged(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	writeTestFile(t, "registry/coder/modules/code-server/main.tf", `variable "agent_id" {}
+variable "port" {}`)
+	releases := moduleReleaseIndex{
+		releases: map[string][]moduleRelease{
+			"coder/code-server": {{version: goversion.Must(goversion.NewVersion("1.5.2"))}},
+		},
+	}
+	template := parseTestTerraform(t, `
+module "current" {
+  source   = "registry.coder.com/coder/code-server/coder"
+  version  = "1.5.2"
+  agent_id = "x"
+  folder   = "/home/coder"
+}
+module "older" {
+  source   = "registry.coder.com/coder/code-server/coder"
+  version  = "1.3.1"
+  agent_id = "x"
+  folder   = "/home/coder"
+}
+module {
+  source = "registry.coder.com/coder/code-server/coder"
+}`)
+
+	errs, warnings := validateTemplateModuleReferences([]coderResourceTerraform{template}, releases, registryAliases{})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `main.tf:6": argument "folder" is not a variable of module "coder/code-server" at version 1.5.2`) {
+		t.Errorf("expected an error for the current version, got: %v", errs)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Error(), `main.tf:12": argument "folder" is not a variable of module "coder/code-server" at its current version 1.5.2; version 1.3.1 could not be checked`) {
+		t.Errorf("expected a warning for the older version, got: %v", warnings)
+	}
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"golang.org/x/xerrors"
+)
+
+// terraformFile is a single parsed .tf file belonging to a module or template.
+type terraformFile struct {
+	filePath string
+	src      []byte
+	body     *hclsyntax.Body
+}
+
+// coderResourceTerraform represents all Terraform configuration files that live at the top level of a single module
+// or template directory. Nested directories are not included, because Terraform does not load them either.
+type coderResourceTerraform struct {
+	resourceType string
+	dirPath      string
+	files        []terraformFile
+}
+
+// terraformBlock pairs a parsed block with the file it was found in, so that diagnostics can always point back at
+// a concrete location.
+type terraformBlock struct {
+	filePath string
+	block    *hclsyntax.Block
+}
+
+// blocks returns every top-level block of the given type whose leading labels match the provided labels. For
+// example, blocks("data", "coder_parameter") returns every coder_parameter data source.
+func (t coderResourceTerraform) blocks(blockType string, labels ...string) []terraformBlock {
+	var found []terraformBlock
+	for _, f := range t.files {
+		for _, b := range f.body.Blocks {
+			if b.Type != blockType || len(b.Labels) < len(labels) {
+				continue
+			}
+			if !slices.Equal(b.Labels[:len(labels)], labels) {
+				continue
+			}
+			found = append(found, terraformBlock{filePath: f.filePath, block: b})
+		}
+	}
+	return found
+}
+
+// locals returns every local value defined in the configuration, keyed by name.
+func (t coderResourceTerraform) locals() map[string]*hclsyntax.Attribute {
+	found := map[string]*hclsyntax.Attribute{}
+	for _, b := range t.blocks("locals") {
+		for name, attr := range b.block.Body.Attributes {
+			found[name] = attr
+		}
+	}
+	return found
+}
+
+// readFilePaths returns the path of every file that the configuration reads with file or templatefile, for calls whose
+// path is known at parse time, e.g. "<dirPath>/run.sh" for templatefile("${path.module}/run.sh", {}). Paths are sorted
+// and not checked for existence.
+func (t coderResourceTerraform) readFilePaths() []string {
+	evalCtx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"path": cty.ObjectVal(map[string]cty.Value{"module": cty.StringVal(t.dirPath)}),
+		},
+	}
+	var paths []string
+	for _, f := range t.files {
+		_ = hclsyntax.VisitAll(f.body, func(node hclsyntax.Node) hcl.Diagnostics {
+			call, ok := node.(*hclsyntax.FunctionCallExpr)
+			if !ok || (call.Name != "file" && call.Name != "templatefile") || len(call.Args) == 0 {
+				return nil
+			}
+			value, diags := call.Args[0].Value(evalCtx)
+			if diags.HasErrors() || !value.IsWhollyKnown() || value.IsNull() || value.Type() != cty.String {
+				return nil
+			}
+			paths = append(paths, path.Clean(value.AsString()))
+			return nil
+		})
+	}
+	slices.Sort(paths)
+	return slices.Compact(paths)
+}
+
+func parseTerraformFile(filePath string, src []byte) (terraformFile, error) {
+	file, diags := hclsyntax.ParseConfig(src, filePath, hcl.InitialPos)
+	if diags.HasErrors() {
+		return terraformFile{}, xerrors.Errorf("failed to parse Terraform: %v", diags)
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return terraformFile{}, xerrors.Errorf("unexpected Terraform body type %T", file.Body)
+	}
+	return terraformFile{filePath: filePath, src: src, body: body}, nil
+}
+
+func parseCoderResourceTerraform(resourceType string, dirPath string) (coderResourceTerraform, []error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return coderResourceTerraform{}, []error{addFilePathToError(dirPath, err)}
+	}
+
+	resource := coderResourceTerraform{
+		resourceType: resourceType,
+		dirPath:      dirPath,
+	}
+	var errs []error
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".tf") {
+			continue
+		}
+
+		filePath := path.Join(dirPath, e.Name())
+		src, err := os.ReadFile(filePath)
+		if err != nil {
+			errs = append(errs, addFilePathToError(filePath, err))
+			continue
+		}
+		tf, err := parseTerraformFile(filePath, src)
+		if err != nil {
+			errs = append(errs, addFilePathToError(filePath, err))
+			continue
+		}
+		resource.files = append(resource.files, tf)
+	}
+	return resource, errs
+}
+
+// aggregateCoderResourceTerraform parses the Terraform files of every module or template in the registry.
+func aggregateCoderResourceTerraform(resourceType string) ([]coderResourceTerraform, error) {
+	if !slices.Contains(supportedResourceTypes, resourceType) {
+		return nil, xerrors.Errorf("cannot process unknown resource type %q", resourceType)
+	}
+
+	namespaceDirs, err := os.ReadDir(rootRegistryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []coderResourceTerraform
+	var errs []error
+	for _, nDir := range namespaceDirs {
+		if !nDir.IsDir() {
+			continue
+		}
+
+		resourceRootPath := path.Join(rootRegistryPath, nDir.Name(), resourceType)
+		resourceDirs, err := os.ReadDir(resourceRootPath)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		for _, rd := range resourceDirs {
+			if !rd.IsDir() || rd.Name() == ".coder" {
+				continue
+			}
+
+			resource, parseErrs := parseCoderResourceTerraform(resourceType, path.Join(resourceRootPath, rd.Name()))
+			if len(parseErrs) != 0 {
+				errs = append(errs, parseErrs...)
+				continue
+			}
+			resources = append(resources, resource)
+		}
+	}
+
+	if len(errs) != 0 {
+		return nil, validationPhaseError{
+			phase:  validationPhaseTerraform,
+			errors: errs,
+		}
+	}
+	return resources, nil
+}
+
+// literalValue evaluates an expression without any variables or functions in scope. It only succeeds for expressions
+// that are fully known at parse time, like string, number and boolean literals, or templates without interpolations.
+func literalValue(expr hclsyntax.Expression) (cty.Value, bool) {
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() {
+		return cty.NilVal, false
+	}
+	return val, true
+}
+
+// literalString returns the value of an expression as a string if it is a literal that can be converted to one.
+// Numbers and booleans are converted the same way Terraform would.
+func literalString(expr hclsyntax.Expression) (string, bool) {
+	val, ok := literalValue(expr)
+	if !ok {
+		return "", false
+	}
+	converted, err := convert.Convert(val, cty.String)
+	if err != nil {
+		return "", false
+	}
+	return converted.AsString(), true
+}
+
+func literalBool(expr hclsyntax.Expression) (value bool, ok bool) {
+	val, ok := literalValue(expr)
+	if !ok {
+		return false, false
+	}
+	converted, err := convert.Convert(val, cty.Bool)
+	if err != nil {
+		return false, false
+	}
+	return converted.True(), true
+}
+
+// referencedNames returns the second segment of every traversal in the expression that starts with the given root
+// name, e.g. referencedNames(expr, "local") returns "image" for an expression containing "local.image".
+func referencedNames(expr hclsyntax.Expression, root string) []string {
+	var names []string
+	for _, traversal := range expr.Variables() {
+		if traversal.RootName() != root || len(traversal) < 2 {
+			continue
+		}
+		attr, ok := traversal[1].(hcl.TraverseAttr)
+		if !ok {
+			continue
+		}
+		if !slices.Contains(names, attr.Name) {
+			names = append(names, attr.Name)
+		}
+	}
+	return names
+}
+
+// referencedDataSources returns the names of every data source of the given type referenced by the expression, e.g.
+// "os" for an expression containing "data.coder_parameter.os.value".
+func referencedDataSources(expr hclsyntax.Expression, dataType string) []string {
+	var names []string
+	for _, traversal := range expr.Variables() {
+		if traversal.RootName() != "data" || len(traversal) < 3 {
+			continue
+		}
+		typeAttr, ok := traversal[1].(hcl.TraverseAttr)
+		if !ok || typeAttr.Name != dataType {
+			continue
+		}
+		nameAttr, ok := traversal[2].(hcl.TraverseAttr)
+		if !ok {
+			continue
+		}
+		if !slices.Contains(names, nameAttr.Name) {
+			names = append(names, nameAttr.Name)
+		}
+	}
+	return names
+}
+
+func validateAllCoderResourceTerraform() error {
+	modules, err := aggregateCoderResourceTerraform("modules")
+	if err != nil {
+		return err
+	}
+	templates, err := aggregateCoderResourceTerraform("templates")
+	if err != nil {
+		return err
+	}
+	resources := slices.Concat(modules, templates)
+	logger.Info(context.Background(), "processing Terraform files", "num_resources", len(resources))
+
+	aliases, err := loadRegistryAliases()
+	if err != nil {
+		return err
+	}
+	releases, err := loadModuleReleaseIndex(modules, aliases)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, tf := range resources {
+		errs = append(errs, validateCoderParameters(tf)...)
+		errs = append(errs, validateTerraformIcons(tf)...)
+	}
+	referenceErrs, referenceWarnings := validateTemplateModuleReferences(templates, releases, aliases)
+	errs = append(errs, referenceErrs...)
+	for _, w := range referenceWarnings {
+		logger.Warn(context.Background(), w.Error())
+	}
+	if len(errs) != 0 {
+		return validationPhaseError{
+			phase:  validationPhaseTerraform,
+			errors: errs,
+		}
+	}
+
+	logger.Info(context.Background(), "processed Terraform files as valid", "num_resources", len(resources))
+	return nil
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// rootExamplesPath holds the module, template, and namespace scaffolding that new contributions are created from.
+const rootExamplesPath = "./examples"
+
+// watchRoots lists the directories that watch mode polls for changes.
+var watchRoots = []string{rootRegistryPath, rootIconsPath, rootExamplesPath}
+
+// watchErrorLocationRe matches the "path": or "path:line": prefix that addFilePathToError and addRangeToError add.
+var watchErrorLocationRe = regexp.MustCompile(`^"([^"]+?)(?::(\d+))?": `)
+
+// watchDiagnostic is a single problem found by watch mode.
+type watchDiagnostic struct {
+	filePath string
+	line     int
+	severity string
+	message  string
+}
+
+// key identifies a diagnostic across runs. It leaves out the line, so that editing the lines above a problem doesn't
+// report it as both fixed and newly introduced.
+func (d watchDiagnostic) key() string {
+	return d.filePath + "\x00" + d.severity + "\x00" + d.message
+}
+
+func (d watchDiagnostic) String() string {
+	location := d.filePath
+	if d.line > 0 {
+		location += ":" + strconv.Itoa(d.line)
+	}
+	return fmt.Sprintf("%s %s: %s", d.severity, location, d.message)
+}
+
+// watchDiagnosticFromError converts a validation error into a diagnostic, taking its location from the prefix added by
+// addFilePathToError or addRangeToError when there is one.
+func watchDiagnosticFromError(unit string, severity string, err error) watchDiagnostic {
+	d := watchDiagnostic{filePath: unit, severity: severity, message: err.Error()}
+	if match := watchErrorLocationRe.FindStringSubmatch(d.message); match != nil {
+		d.filePath = match[1]
+		d.line, _ = strconv.Atoi(match[2])
+		d.message = strings.TrimPrefix(d.message, match[0])
+	}
+	return d
+}
+
+// watchUnitForPath returns the unit that a file belongs to: the part of the registry that is re-validated on its own
+// when the file changes. Units are namespace profiles ("registry/<namespace>"), modules and templates
+// ("registry/<namespace>/modules/<name>"), skills READMEs ("registry/<namespace>/skills"), single icons
+// (".icons/<name>"), and examples ("examples/<kind>").
+func watchUnitForPath(filePath string) (string, bool) {
+	parts := strings.Split(path.Clean(filePath), "/")
+	switch parts[0] {
+	case path.Clean(rootRegistryPath):
+		switch {
+		case len(parts) < 3:
+			return "", false
+		case len(parts) >= 5 && slices.Contains(supportedResourceTypes, parts[2]):
+			return path.Join(parts[:4]...), true
+		case parts[2] == "skills":
+			return path.Join(parts[:3]...), true
+		case len(parts) == 3 || parts[2] == ".images":
+			return path.Join(parts[:2]...), true
+		}
+	case path.Clean(rootIconsPath):
+		if len(parts) == 2 {
+			return filePath, true
+		}
+	case path.Clean(rootExamplesPath):
+		if len(parts) >= 3 {
+			return path.Join(parts[:2]...), true
+		}
+	}
+	return "", false
+}
+
+// fileStamp is what watch mode compares to detect that a file changed.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// registryWatcher keeps the diagnostics of every unit of the registry in memory, so that only the units affected by a
+// change need to be validated again.
+type registryWatcher struct {
+	readmes     *lspServer
+	stamps      map[string]fileStamp
+	diagnostics map[string][]watchDiagnostic
+	// iconDependents maps the path of every icon referenced from README frontmatter to the units that reference it,
+	// so that adding or removing an icon re-validates them.
+	iconDependents map[string][]string
+}
+
+func newRegistryWatcher(vocab tagVocabulary) *registryWatcher {
+	return &registryWatcher{
+		readmes:        newLSPServer(vocab),
+		stamps:         map[string]fileStamp{},
+		diagnostics:    map[string][]watchDiagnostic{},
+		iconDependents: map[string][]string{},
+	}
+}
+
+// scanWatchRoots returns the stamp of every file under the watched directories.
+func scanWatchRoots() (map[string]fileStamp, error) {
+	stamps := map[string]fileStamp{}
+	for _, root := range watchRoots {
+		err := filepath.WalkDir(root, func(filePath string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && filePath == root {
+					return fs.SkipDir
+				}
+				return err
+			}
+			if d.IsDir() {
+				if d.Name() == "node_modules" || d.Name() == ".terraform" {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			stamps[path.Clean(filepath.ToSlash(filePath))] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stamps, nil
+}
+
+// changedPaths returns every file that was added, removed, or modified between two scans, sorted.
+func changedPaths(before map[string]fileStamp, after map[string]fileStamp) []string {
+	var changed []string
+	for filePath, stamp := range after {
+		if prev, ok := before[filePath]; !ok || prev != stamp {
+			changed = append(changed, filePath)
+		}
+	}
+	for filePath := range before {
+		if _, ok := after[filePath]; !ok {
+			changed = append(changed, filePath)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+// affectedUnits returns the units to validate again after the given files changed, sorted.
+func (w *registryWatcher) affectedUnits(paths []string) []string {
+	var units []string
+	for _, filePath := range paths {
+		if unit, ok := watchUnitForPath(filePath); ok {
+			units = append(units, unit)
+		}
+		units = append(units, w.iconDependents[filePath]...)
+	}
+	slices.Sort(units)
+	return slices.Compact(units)
+}
+
+// validateReadme runs the same checks on a README as the language server, and returns the icon it references.
+func (w *registryWatcher) validateReadme(filePath string) (diagnostics []watchDiagnostic, iconPath string) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []watchDiagnostic{{filePath: filePath, severity: "error", message: "README.md is missing"}}, ""
+		}
+		return []watchDiagnostic{watchDiagnosticFromError(filePath, "error", err)}, ""
+	}
+
+	doc := newLSPDocument("", filePath, string(content))
+	for _, d := range w.readmes.diagnose(doc) {
+		severity := "error"
+		if d.Severity != lspSeverityError {
+			severity = "warning"
+		}
+		diagnostics = append(diagnostics, watchDiagnostic{filePath: filePath, line: d.Range.Start.Line + 1, severity: severity, message: d.Message})
+	}
+	if root, _, ok := doc.frontmatter(); ok {
+		if _, iconNode := yamlMappingValue(root, "icon"); iconNode != nil && iconNode.Kind == yaml.ScalarNode {
+			iconPath = path.Join(path.Dir(filePath), iconNode.Value)
+		}
+	}
+	return diagnostics, iconPath
+}
+
+// validateTerraformUnit runs the checks that apply to the Terraform of a single module, template, or example.
+func validateTerraformUnit(resourceType string, dirPath string) []watchDiagnostic {
+	tf, errs := parseCoderResourceTerraform(resourceType, dirPath)
+	if len(errs) == 0 {
+		errs = append(validateCoderParameters(tf), validateTerraformIcons(tf)...)
+	}
+	var diagnostics []watchDiagnostic
+	for _, err := range errs {
+		diagnostics = append(diagnostics, watchDiagnosticFromError(dirPath, "error", err))
+	}
+	return diagnostics
+}
+
+// validateUnit validates a single unit from scratch. Units that no longer exist have no diagnostics.
+func (w *registryWatcher) validateUnit(unit string) []watchDiagnostic {
+	for iconPath, dependents := range w.iconDependents {
+		w.iconDependents[iconPath] = slices.DeleteFunc(dependents, func(u string) bool { return u == unit })
+	}
+	if _, err := os.Stat(unit); err != nil {
+		return nil
+	}
+
+	var diagnostics []watchDiagnostic
+	parts := strings.Split(unit, "/")
+	switch {
+	case parts[0] == path.Clean(rootIconsPath):
+		content, err := os.ReadFile(unit)
+		if err != nil {
+			return []watchDiagnostic{watchDiagnosticFromError(unit, "error", err)}
+		}
+		for _, err := range validateIconFile(path.Base(unit), content) {
+			diagnostics = append(diagnostics, watchDiagnostic{filePath: unit, severity: "error", message: err.Error()})
+		}
+	case parts[0] == path.Clean(rootExamplesPath):
+		if slices.Contains(supportedResourceTypes, parts[1]) {
+			diagnostics = validateTerraformUnit(parts[1], unit)
+		}
+	default:
+		readmeDiagnostics, iconPath := w.validateReadme(path.Join(unit, "README.md"))
+		diagnostics = readmeDiagnostics
+		if iconPath != "" {
+			w.iconDependents[iconPath] = append(w.iconDependents[iconPath], unit)
+		}
+		if len(parts) == 4 {
+			diagnostics = append(diagnostics, validateTerraformUnit(parts[2], unit)...)
+		}
+	}
+	return diagnostics
+}
+
+// diffDiagnostics returns the diagnostics that are in after but not before, and the ones that are in before but not
+// after.
+func diffDiagnostics(before []watchDiagnostic, after []watchDiagnostic) (introduced []watchDiagnostic, fixed []watchDiagnostic) {
+	count := map[string]int{}
+	for _, d := range before {
+		count[d.key()]++
+	}
+	for _, d := range after {
+		if count[d.key()] > 0 {
+			count[d.key()]--
+			continue
+		}
+		introduced = append(introduced, d)
+	}
+	for _, d := range before {
+		if count[d.key()] > 0 {
+			count[d.key()]--
+			fixed = append(fixed, d)
+		}
+	}
+	return introduced, fixed
+}
+
+// revalidate validates the given units again and returns the diagnostics that were introduced and fixed.
+func (w *registryWatcher) revalidate(units []string) (introduced []watchDiagnostic, fixed []watchDiagnostic) {
+	for _, unit := range units {
+		after := w.validateUnit(unit)
+		unitIntroduced, unitFixed := diffDiagnostics(w.diagnostics[unit], after)
+		introduced = append(introduced, unitIntroduced...)
+		fixed = append(fixed, unitFixed...)
+		if len(after) == 0 {
+			delete(w.diagnostics, unit)
+		} else {
+			w.diagnostics[unit] = after
+		}
+	}
+	return introduced, fixed
+}
+
+// total returns the number of diagnostics across all units.
+func (w *registryWatcher) total() int {
+	n := 0
+	for _, diagnostics := range w.diagnostics {
+		n += len(diagnostics)
+	}
+	return n
+}
+
+// poll scans the watched directories and validates the units affected by any change since the last poll. The first
+// poll validates every unit.
+func (w *registryWatcher) poll() (changed []string, units []string, introduced []watchDiagnostic, fixed []watchDiagnostic, err error) {
+	stamps, err := scanWatchRoots()
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	changed = changedPaths(w.stamps, stamps)
+	w.stamps = stamps
+	units = w.affectedUnits(changed)
+	introduced, fixed = w.revalidate(units)
+	return changed, units, introduced, fixed, nil
+}
+
+// printWatchReport prints what changed in a single poll.
+func printWatchReport(out io.Writer, now time.Time, changed []string, units []string, introduced []watchDiagnostic, fixed []watchDiagnostic, total int) {
+	what := fmt.Sprintf("%d file(s) changed", len(changed))
+	if len(changed) == 1 {
+		what = changed[0] + " changed"
+	}
+	fmt.Fprintf(out, "[%s] %s, re-validated %d unit(s): %d new, %d fixed, %d total\n",
+		now.Format(time.TimeOnly), what, len(units), len(introduced), len(fixed), total)
+	for _, d := range introduced {
+		fmt.Fprintf(out, "  + %s\n", d)
+	}
+	for _, d := range fixed {
+		fmt.Fprintf(out, "  - %s\n", d)
+	}
+}
+
+// runWatchMode validates every unit of the registry, then polls for changes until the process is interrupted. Checks
+// that span the whole registry, like CODEOWNERS, the contributor status policy, and template module references, only
+// run in a full validation.
+func runWatchMode(interval time.Duration) error {
+	vocab, err := loadTagVocabulary()
+	if err != nil {
+		return err
+	}
+	w := newRegistryWatcher(vocab)
+
+	_, units, introduced, _, err := w.poll()
+	if err != nil {
+		return err
+	}
+	for _, d := range introduced {
+		fmt.Println(d)
+	}
+	logger.Info(context.Background(), "watching the registry for changes", "num_units", len(units), "num_diagnostics", w.total(), "interval", interval.String())
+
+	for {
+		time.Sleep(interval)
+		changed, units, introduced, fixed, err := w.poll()
+		if err != nil {
+			return err
+		}
+		if len(changed) == 0 {
+			continue
+		}
+		printWatchReport(os.Stdout, time.Now(), changed, units, introduced, fixed, w.total())
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"golang.org/x/xerrors"
+)
+
+func TestWatchUnitForPath(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		filePath string
+		expected string
+	}{
+		{filePath: "registry/acme/README.md", expected: "registry/acme"},
+		{filePath: "registry/acme/.images/avatar.png", expected: "registry/acme"},
+		{filePath: "registry/acme/modules/code-server/main.tf", expected: "registry/acme/modules/code-server"},
+		{filePath: "registry/acme/templates/docker/scripts/init.sh", expected: "registry/acme/templates/docker"},
+		{filePath: "registry/acme/skills/README.md", expected: "registry/acme/skills"},
+		{filePath: ".icons/code.svg", expected: ".icons/code.svg"},
+		{filePath: "examples/modules/run.sh", expected: "examples/modules"},
+		{filePath: "registry/README.md"},
+		{filePath: "registry/acme/modules/README.md"},
+		{filePath: "scripts/new_module.sh"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.filePath, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := watchUnitForPath(tc.filePath)
+			if got != tc.expected || ok != (tc.expected != "") {
+				t.Errorf("expected unit %q, got %q (ok: %v)", tc.expected, got, ok)
+			}
+		})
+	}
+}
+
+func TestWatchDiagnosticFromError(t *testing.T) {
+	t.Parallel()
+
+	d := watchDiagnosticFromError("registry/acme/modules/foo", "error", addRangeToError(hcl.Range{Filename: "registry/acme/modules/foo/main.tf", Start: hcl.Pos{Line: 12}}, xerrors.New("option is missing required 'value' attribute")))
+	if d.String() != "error registry/acme/modules/foo/main.tf:12: option is missing required 'value' attribute" {
+		t.Errorf("unexpected diagnostic: %s", d)
+	}
+	d = watchDiagnosticFromError("registry/acme/modules/foo", "error", xerrors.New("failed to parse"))
+	if d.String() != "error registry/acme/modules/foo: failed to parse" {
+		t.Errorf("unexpected diagnostic: %s", d)
+	}
+}
+
+func TestDiffDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	a := watchDiagnostic{filePath: "README.md", line: 3, severity: "error", message: "a"}
+	b := watchDiagnostic{filePath: "README.md", line: 4, severity: "warning", message: "b"}
+	movedA := watchDiagnostic{filePath: "README.md", line: 5, severity: "error", message: "a"}
+	c := watchDiagnostic{filePath: "README.md", line: 6, severity: "error", message: "c"}
+
+	introduced, fixed := diffDiagnostics([]watchDiagnostic{a, b, b}, []watchDiagnostic{movedA, b, c})
+	if !slices.Equal(introduced, []watchDiagnostic{c}) {
+		t.Errorf("expected only c to be introduced, got %v", introduced)
+	}
+	if !slices.Equal(fixed, []watchDiagnostic{b}) {
+		t.Errorf("expected one b to be fixed, got %v", fixed)
+	}
+}
+
+func writeTestFile(t *testing.T, filePath string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(path.Dir(filePath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegistryWatcher(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	const readmePath = "registry/acme/modules/code-server/README.md"
+	readme := func(tags string) string {
+		return "---\ndisplay_name: Code Server\ndescription: VS Code in the browser\nicon: ../../../../.icons/code.svg\ntags: [" + tags + "]\n---\n\n# Code Server\n"
+	}
+	writeTestFile(t, readmePath, readme("editor"))
+	writeTestFile(t, "registry/acme/modules/code-server/main.tf", "")
+	w := newRegistryWatcher(testLSPVocabulary)
+
+	_, units, introduced, _, err := w.poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(units, []string{"registry/acme/modules/code-server"}) {
+		t.Errorf("expected the module to be validated, got %v", units)
+	}
+	hasDiagnostic := func(diagnostics []watchDiagnostic, substr string) bool {
+		return slices.ContainsFunc(diagnostics, func(d watchDiagnostic) bool { return strings.Contains(d.message, substr) })
+	}
+	if !hasDiagnostic(introduced, `tag "editor" is a synonym`) || !hasDiagnostic(introduced, "icon file does not exist") {
+		t.Errorf("expected the synonym and the missing icon to be reported, got %v", introduced)
+	}
+
+	// Changing the README only re-validates its module.
+	writeTestFile(t, readmePath, readme("ide"))
+	changed, units, introduced, fixed, err := w.poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(changed, []string{readmePath}) || !slices.Equal(units, []string{"registry/acme/modules/code-server"}) {
+		t.Errorf("expected only the README to change, got %v (units %v)", changed, units)
+	}
+	if len(introduced) != 0 || len(fixed) != 1 || !hasDiagnostic(fixed, `tag "editor" is a synonym`) {
+		t.Errorf("expected the synonym to be fixed, got introduced %v, fixed %v", introduced, fixed)
+	}
+
+	// Adding the icon re-validates the module that references it.
+	writeTestFile(t, ".icons/code.svg", `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24"></svg>`)
+	_, units, _, fixed, err = w.poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(units, []string{".icons/code.svg", "registry/acme/modules/code-server"}) {
+		t.Errorf("expected the icon and the module to be validated, got %v", units)
+	}
+	if !hasDiagnostic(fixed, "icon file does not exist") {
+		t.Errorf("expected the missing icon to be fixed, got %v", fixed)
+	}
+
+	// Nothing changed, so nothing is validated.
+	changed, units, _, _, err = w.poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 0 || len(units) != 0 {
+		t.Errorf("expected no changes, got %v (units %v)", changed, units)
+	}
+}
@@ -48,7 +48,7 @@ data "coder_parameter" "cpu" {
   description  = "Number of vCPUs."
   type         = "number"
   default      = 2
-  icon         = "https://raw.githubusercontent.com/matifali/logos/main/cpu-3.svg"
+  icon         = "/icon/desktop.svg"
   mutable      = true
   order        = 1
   validation {
@@ -54,7 +54,7 @@ data "coder_parameter" "droplet_image" {
   description  = "Which Droplet image would you like to use?"
   default      = "ubuntu-22-04-x64"
   type         = "string"
-  mutable      = false
+  mutable      = true
   option {
     name  = "AlmaLinux 9"
     value = "almalinux-9-x64"
@@ -31,7 +31,7 @@ data "coder_parameter" "cpu" {
   description  = "The number of CPUs to allocate to the workspace (1-8)"
   type         = "number"
   default      = "1"
-  icon         = "https://raw.githubusercontent.com/matifali/logos/main/cpu-3.svg"
+  icon         = "/icon/desktop.svg"
   mutable      = true
   validation {
     min = 1
@@ -12,7 +12,7 @@ terraform {
 data "coder_parameter" "home_disk" {
   name        = "Disk Size"
   description = "How large should the disk storing the home directory be?"
-  icon        = "https://cdn-icons-png.flaticon.com/512/2344/2344147.png"
+  icon        = "/emojis/1f4be.png"
   type        = "number"
   default     = 10
   mutable     = true
@@ -100,7 +100,7 @@ resource "coder_agent" "main" {
 }
 
 module "code-server" {
-  source   = "registry.coder.com/modules/code-server/coder"
+  source   = "registry.coder.com/coder/code-server/coder"
   version  = "1.3.1"
   agent_id = coder_agent.main.id
   order    = 1
@@ -176,6 +176,7 @@ data "coder_parameter" "base_image" {
   type        = "string"
   form_type   = "radio"
   default     = "debian_trixie"
+  mutable     = true
 
   option {
     name  = "Debian 13 (Trixie)"
@@ -170,7 +170,7 @@ data "coder_parameter" "container_image" {
   display_name = "Container Image"
   type         = "string"
   default      = "codercom/example-universal:ubuntu"
-  mutable      = false
+  mutable      = true
 }
 data "coder_parameter" "preview_port" {
   name         = "preview_port"
@@ -200,26 +200,30 @@ resource "coder_env" "claude_system_prompt" {
 
 module "coder-login" {
   count    = data.coder_workspace.me.start_count
-  source   = "registry.coder.com/modules/coder-login/coder"
+  source   = "registry.coder.com/coder/coder-login/coder"
+  version  = "~> 1.0"
   agent_id = coder_agent.dev.id
 }
 
 module "dotfiles" {
   count    = data.coder_workspace.me.start_count
-  source   = "registry.coder.com/modules/dotfiles/coder"
+  source   = "registry.coder.com/coder/dotfiles/coder"
+  version  = "~> 1.0"
   agent_id = coder_agent.dev.id
 }
 
 module "code-server" {
   count    = data.coder_workspace.me.start_count
-  source   = "registry.coder.com/modules/code-server/coder"
+  source   = "registry.coder.com/coder/code-server/coder"
+  version  = "~> 1.0"
   agent_id = coder_agent.dev.id
   folder   = "/home/coder/projects"
 }
 
 module "git-config" {
   count    = data.coder_workspace.me.start_count
-  source   = "registry.coder.com/modules/git-config/coder"
+  source   = "registry.coder.com/coder/git-config/coder"
+  version  = "~> 1.0"
   agent_id = coder_agent.dev.id
 }
 
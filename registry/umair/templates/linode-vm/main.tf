@@ -277,7 +277,7 @@ data "coder_parameter" "instance_image" {
   description  = "Which Linode image would you like to use?"
   default      = "linode/ubuntu24.04"
   type         = "string"
-  mutable      = false
+  mutable      = true
 
   option {
     name  = "Ubuntu 24.04 LTS"